@@ -5,6 +5,7 @@ import (
 	"github.com/Cryptochain-VON/accounts"
 	"github.com/Cryptochain-VON/accounts/keystore"
 	"github.com/Cryptochain-VON/common"
+	"github.com/Cryptochain-VON/core/types"
 	"github.com/Cryptochain-VON/crypto"
 )
 const (
@@ -76,6 +77,32 @@ func (ks *KeyStore) SignTxPassphrase(account *Account, passphrase string, tx *Tr
 	}
 	return &Transaction{signed}, nil
 }
+func (ks *KeyStore) SignTxWithSigner(account *Account, tx *Transaction, signer *TxSigner) (*Transaction, error) {
+	sig, err := ks.keystore.SignHash(account.account, signer.signer.Hash(tx.tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return signer.WithSignature(tx, sig)
+}
+func (ks *KeyStore) SignTxPassphraseWithSigner(account *Account, passphrase string, tx *Transaction, signer *TxSigner) (*Transaction, error) {
+	sig, err := ks.keystore.SignHashWithPassphrase(account.account, passphrase, signer.signer.Hash(tx.tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return signer.WithSignature(tx, sig)
+}
+func (ks *KeyStore) NewSigner(signer *TxSigner) *MobileSigner {
+	return &MobileSigner{
+		sign: func(rawSigner types.Signer, addr common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			sig, err := ks.keystore.SignHash(accounts.Account{Address: addr}, rawSigner.Hash(tx).Bytes())
+			if err != nil {
+				return nil, err
+			}
+			return tx.WithSignature(rawSigner, sig)
+		},
+		signer: signer,
+	}
+}
 func (ks *KeyStore) Unlock(account *Account, passphrase string) error {
 	return ks.keystore.TimedUnlock(account.account, passphrase, 0)
 }
@@ -0,0 +1,153 @@
+package geth
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	ethereum "github.com/Cryptochain-VON"
+	"github.com/Cryptochain-VON/common/hexutil"
+	"github.com/Cryptochain-VON/core/types"
+	"github.com/Cryptochain-VON/rpc"
+)
+type BatchRequest struct {
+	elems    []rpc.BatchElem
+	executed bool
+	err      error
+}
+func NewBatchRequest() *BatchRequest {
+	return new(BatchRequest)
+}
+func (b *BatchRequest) Size() int {
+	return len(b.elems)
+}
+func (b *BatchRequest) add(result interface{}, method string, args ...interface{}) int {
+	b.elems = append(b.elems, rpc.BatchElem{Method: method, Args: args, Result: result})
+	b.executed = false
+	return len(b.elems) - 1
+}
+func (b *BatchRequest) AddGetBalanceAt(account *Address, number int64) int {
+	return b.add(new(hexutil.Big), "eth_getBalance", account.address, toBlockNumArg(number))
+}
+func (b *BatchRequest) AddGetNonceAt(account *Address, number int64) int {
+	return b.add(new(hexutil.Uint64), "eth_getTransactionCount", account.address, toBlockNumArg(number))
+}
+func (b *BatchRequest) AddGetCodeAt(account *Address, number int64) int {
+	return b.add(new(hexutil.Bytes), "eth_getCode", account.address, toBlockNumArg(number))
+}
+func (b *BatchRequest) AddGetStorageAt(account *Address, key *Hash, number int64) int {
+	return b.add(new(hexutil.Bytes), "eth_getStorageAt", account.address, key.hash, toBlockNumArg(number))
+}
+func (b *BatchRequest) AddCallContract(msg *CallMsg, number int64) int {
+	return b.add(new(hexutil.Bytes), "eth_call", toCallArg(msg.msg), toBlockNumArg(number))
+}
+func (b *BatchRequest) AddGetTransactionReceipt(hash *Hash) int {
+	return b.add(new(*types.Receipt), "eth_getTransactionReceipt", hash.hash)
+}
+func (b *BatchRequest) result(index int, method string) (interface{}, error) {
+	if index < 0 || index >= len(b.elems) {
+		return nil, errors.New("index out of bounds")
+	}
+	if elem := b.elems[index]; elem.Method != method {
+		return nil, fmt.Errorf("batch item %d is %s, not %s", index, elem.Method, method)
+	}
+	if !b.executed {
+		return nil, errBatchNotExecuted
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	if err := b.elems[index].Error; err != nil {
+		return nil, err
+	}
+	return b.elems[index].Result, nil
+}
+func (b *BatchRequest) GetError(index int) string {
+	if index < 0 || index >= len(b.elems) {
+		return ""
+	}
+	if !b.executed {
+		return errBatchNotExecuted.Error()
+	}
+	if b.err != nil {
+		return b.err.Error()
+	}
+	if b.elems[index].Error == nil {
+		return ""
+	}
+	return b.elems[index].Error.Error()
+}
+func (b *BatchRequest) GetBalance(index int) (balance *BigInt, _ error) {
+	res, err := b.result(index, "eth_getBalance")
+	if err != nil {
+		return nil, err
+	}
+	return &BigInt{(*big.Int)(res.(*hexutil.Big))}, nil
+}
+func (b *BatchRequest) GetNonce(index int) (nonce int64, _ error) {
+	res, err := b.result(index, "eth_getTransactionCount")
+	if err != nil {
+		return 0, err
+	}
+	return int64(*res.(*hexutil.Uint64)), nil
+}
+func (b *BatchRequest) GetCode(index int) (code []byte, _ error) {
+	res, err := b.result(index, "eth_getCode")
+	if err != nil {
+		return nil, err
+	}
+	return *res.(*hexutil.Bytes), nil
+}
+func (b *BatchRequest) GetStorage(index int) (storage []byte, _ error) {
+	res, err := b.result(index, "eth_getStorageAt")
+	if err != nil {
+		return nil, err
+	}
+	return *res.(*hexutil.Bytes), nil
+}
+func (b *BatchRequest) GetCallResult(index int) (output []byte, _ error) {
+	res, err := b.result(index, "eth_call")
+	if err != nil {
+		return nil, err
+	}
+	return *res.(*hexutil.Bytes), nil
+}
+func (b *BatchRequest) GetReceipt(index int) (receipt *Receipt, _ error) {
+	res, err := b.result(index, "eth_getTransactionReceipt")
+	if err != nil {
+		return nil, err
+	}
+	rawReceipt := *res.(**types.Receipt)
+	if rawReceipt == nil {
+		return nil, ethereum.NotFound
+	}
+	return &Receipt{rawReceipt}, nil
+}
+func (ec *EthereumClient) ExecuteBatch(ctx *Context, batch *BatchRequest) error {
+	batch.err = ec.rpc.BatchCallContext(ctx.context, batch.elems)
+	batch.executed = true
+	return batch.err
+}
+func toBlockNumArg(number int64) string {
+	if number < 0 {
+		return "latest"
+	}
+	return hexutil.EncodeBig(big.NewInt(number))
+}
+func toCallArg(msg ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["data"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	return arg
+}
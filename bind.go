@@ -1,5 +1,6 @@
 package geth
 import (
+	"errors"
 	"math/big"
 	"strings"
 	"github.com/Cryptochain-VON/accounts/abi"
@@ -12,10 +13,15 @@ type Signer interface {
 	Sign(*Address, *Transaction) (tx *Transaction, _ error)
 }
 type MobileSigner struct {
-	sign bind.SignerFn
+	sign   bind.SignerFn
+	signer *TxSigner
 }
+func (s *MobileSigner) GetTxSigner() *TxSigner { return s.signer }
 func (s *MobileSigner) Sign(addr *Address, unsignedTx *Transaction) (signedTx *Transaction, _ error) {
-	sig, err := s.sign(types.EIP155Signer{}, addr.address, unsignedTx.tx)
+	if s.signer == nil {
+		return nil, errors.New("no transaction signer configured")
+	}
+	sig, err := s.sign(s.signer.signer, addr.address, unsignedTx.tx)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,303 @@
+package geth
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"github.com/Cryptochain-VON/common"
+	"github.com/Cryptochain-VON/core/types"
+	"github.com/Cryptochain-VON/log"
+	"github.com/Cryptochain-VON/rlp"
+	"github.com/VictoriaMetrics/fastcache"
+	lru "github.com/hashicorp/golang-lru"
+)
+var (
+	cacheBlockPrefix   = []byte("b")
+	cacheHeaderPrefix  = []byte("h")
+	cacheTxPrefix      = []byte("t")
+	cacheReceiptPrefix = []byte("r")
+	cacheNumberPrefix  = []byte("n")
+)
+type CacheConfig struct {
+	MemoryItems   int
+	DiskDir       string
+	DiskBytes     int
+	FinalityDepth int
+}
+var defaultCacheConfig = CacheConfig{
+	MemoryItems:   1024,
+	DiskBytes:     32 * 1024 * 1024,
+	FinalityDepth: 64,
+}
+func NewCacheConfig() *CacheConfig {
+	config := defaultCacheConfig
+	return &config
+}
+type responseCache struct {
+	memory   *lru.Cache
+	disk     *fastcache.Cache
+	diskDir  string
+	depth    uint64
+	head     uint64
+	memHits  uint64
+	diskHits uint64
+	misses   uint64
+	lock     sync.Mutex
+}
+func (ec *EthereumClient) EnableCache(config *CacheConfig) error {
+	if ec.cache != nil {
+		return errors.New("cache already enabled")
+	}
+	if config == nil {
+		config = NewCacheConfig()
+	}
+	if config.MemoryItems <= 0 {
+		return errors.New("cache needs at least one memory item")
+	}
+	memory, err := lru.New(config.MemoryItems)
+	if err != nil {
+		return err
+	}
+	cache := &responseCache{
+		memory:  memory,
+		diskDir: config.DiskDir,
+		depth:   uint64(config.FinalityDepth),
+	}
+	if config.DiskDir != "" {
+		if config.DiskBytes <= 0 {
+			config.DiskBytes = defaultCacheConfig.DiskBytes
+		}
+		if err := os.MkdirAll(config.DiskDir, 0700); err != nil {
+			return err
+		}
+		cache.disk = fastcache.LoadFromFileOrNew(config.DiskDir, config.DiskBytes)
+	}
+	ec.cache = cache
+	return nil
+}
+func (ec *EthereumClient) FlushCache() error {
+	if ec.cache == nil || ec.cache.disk == nil {
+		return nil
+	}
+	return ec.cache.disk.SaveToFile(ec.cache.diskDir)
+}
+func (ec *EthereumClient) PurgeCache() {
+	if ec.cache == nil {
+		return
+	}
+	ec.cache.memory.Purge()
+	if ec.cache.disk != nil {
+		ec.cache.disk.Reset()
+	}
+}
+func (ec *EthereumClient) GetCacheStats() *CacheStats {
+	if ec.cache == nil {
+		return new(CacheStats)
+	}
+	stats := &CacheStats{
+		memHits:  atomic.LoadUint64(&ec.cache.memHits),
+		diskHits: atomic.LoadUint64(&ec.cache.diskHits),
+		misses:   atomic.LoadUint64(&ec.cache.misses),
+		items:    ec.cache.memory.Len(),
+	}
+	if ec.cache.disk != nil {
+		var disk fastcache.Stats
+		ec.cache.disk.UpdateStats(&disk)
+		stats.diskItems = disk.EntriesCount
+		stats.diskBytes = disk.BytesSize
+	}
+	return stats
+}
+func (c *responseCache) close() {
+	if c.disk == nil {
+		return
+	}
+	if err := c.disk.SaveToFile(c.diskDir); err != nil {
+		log.Warn("Failed to persist response cache", "dir", c.diskDir, "err", err)
+	}
+	c.disk.Reset()
+}
+func cacheKey(prefix []byte, hash common.Hash) []byte {
+	return append(append([]byte{}, prefix...), hash[:]...)
+}
+func cacheNumberKey(number uint64) []byte {
+	key := make([]byte, len(cacheNumberPrefix)+8)
+	copy(key, cacheNumberPrefix)
+	binary.BigEndian.PutUint64(key[len(cacheNumberPrefix):], number)
+	return key
+}
+func (c *responseCache) observeHead(header *types.Header) {
+	if c == nil || header == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if number := header.Number.Uint64(); number > c.head {
+		c.head = number
+	}
+}
+func (c *responseCache) finalized(number uint64) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return number+c.depth <= c.head
+}
+func (c *responseCache) get(key []byte, decode func([]byte) (interface{}, error)) interface{} {
+	if c == nil {
+		return nil
+	}
+	item, fromDisk := c.lookup(key, decode)
+	switch {
+	case item == nil:
+		atomic.AddUint64(&c.misses, 1)
+	case fromDisk:
+		atomic.AddUint64(&c.diskHits, 1)
+	default:
+		atomic.AddUint64(&c.memHits, 1)
+	}
+	return item
+}
+func (c *responseCache) lookup(key []byte, decode func([]byte) (interface{}, error)) (interface{}, bool) {
+	if item, ok := c.memory.Get(string(key)); ok {
+		return item, false
+	}
+	if c.disk != nil {
+		if blob := c.disk.GetBig(nil, key); len(blob) > 0 {
+			if item, err := decode(blob); err == nil {
+				c.memory.Add(string(key), item)
+				return item, true
+			}
+		}
+	}
+	return nil, false
+}
+func (c *responseCache) put(key []byte, item interface{}, encode func() ([]byte, error)) {
+	c.memory.Add(string(key), item)
+	if c.disk != nil {
+		if blob, err := encode(); err == nil {
+			c.disk.SetBig(key, blob)
+		}
+	}
+}
+func (c *responseCache) hashByNumber(number uint64) (common.Hash, bool) {
+	item, _ := c.lookup(cacheNumberKey(number), func(blob []byte) (interface{}, error) {
+		return common.BytesToHash(blob), nil
+	})
+	if item == nil {
+		return common.Hash{}, false
+	}
+	return item.(common.Hash), true
+}
+func (c *responseCache) putNumber(number uint64, hash common.Hash) {
+	if !c.finalized(number) {
+		return
+	}
+	c.put(cacheNumberKey(number), hash, func() ([]byte, error) { return hash.Bytes(), nil })
+}
+func (c *responseCache) block(hash common.Hash) *types.Block {
+	item := c.get(cacheKey(cacheBlockPrefix, hash), func(blob []byte) (interface{}, error) {
+		block := new(types.Block)
+		return block, rlp.DecodeBytes(blob, block)
+	})
+	if item == nil {
+		return nil
+	}
+	return item.(*types.Block)
+}
+func (c *responseCache) blockByNumber(number uint64) *types.Block {
+	if c == nil {
+		return nil
+	}
+	if hash, ok := c.hashByNumber(number); ok {
+		return c.block(hash)
+	}
+	atomic.AddUint64(&c.misses, 1)
+	return nil
+}
+func (c *responseCache) addBlock(block *types.Block) {
+	if c == nil || block == nil {
+		return
+	}
+	c.put(cacheKey(cacheBlockPrefix, block.Hash()), block, func() ([]byte, error) { return rlp.EncodeToBytes(block) })
+	c.putNumber(block.NumberU64(), block.Hash())
+}
+func (c *responseCache) header(hash common.Hash) *types.Header {
+	item := c.get(cacheKey(cacheHeaderPrefix, hash), func(blob []byte) (interface{}, error) {
+		header := new(types.Header)
+		return header, rlp.DecodeBytes(blob, header)
+	})
+	if item == nil {
+		return nil
+	}
+	return item.(*types.Header)
+}
+func (c *responseCache) headerByNumber(number uint64) *types.Header {
+	if c == nil {
+		return nil
+	}
+	if hash, ok := c.hashByNumber(number); ok {
+		if header := c.header(hash); header != nil {
+			return header
+		}
+		if block := c.block(hash); block != nil {
+			return block.Header()
+		}
+		return nil
+	}
+	atomic.AddUint64(&c.misses, 1)
+	return nil
+}
+func (c *responseCache) addHeader(header *types.Header) {
+	if c == nil || header == nil {
+		return
+	}
+	c.put(cacheKey(cacheHeaderPrefix, header.Hash()), header, func() ([]byte, error) { return rlp.EncodeToBytes(header) })
+	c.putNumber(header.Number.Uint64(), header.Hash())
+}
+func (c *responseCache) transaction(hash common.Hash) *types.Transaction {
+	item := c.get(cacheKey(cacheTxPrefix, hash), func(blob []byte) (interface{}, error) {
+		tx := new(types.Transaction)
+		return tx, rlp.DecodeBytes(blob, tx)
+	})
+	if item == nil {
+		return nil
+	}
+	return item.(*types.Transaction)
+}
+func (c *responseCache) addTransaction(tx *types.Transaction) {
+	if c == nil || tx == nil {
+		return
+	}
+	c.put(cacheKey(cacheTxPrefix, tx.Hash()), tx, func() ([]byte, error) { return rlp.EncodeToBytes(tx) })
+}
+func (c *responseCache) receipt(hash common.Hash) *types.Receipt {
+	item := c.get(cacheKey(cacheReceiptPrefix, hash), func(blob []byte) (interface{}, error) {
+		receipt := new(types.Receipt)
+		return receipt, json.Unmarshal(blob, receipt)
+	})
+	if item == nil {
+		return nil
+	}
+	return item.(*types.Receipt)
+}
+func (c *responseCache) addReceipt(receipt *types.Receipt) {
+	if c == nil || receipt == nil || receipt.BlockNumber == nil || !c.finalized(receipt.BlockNumber.Uint64()) {
+		return
+	}
+	c.put(cacheKey(cacheReceiptPrefix, receipt.TxHash), receipt, func() ([]byte, error) { return json.Marshal(receipt) })
+}
+type CacheStats struct {
+	memHits   uint64
+	diskHits  uint64
+	misses    uint64
+	items     int
+	diskItems uint64
+	diskBytes uint64
+}
+func (s *CacheStats) GetMemoryHits() int64 { return int64(s.memHits) }
+func (s *CacheStats) GetDiskHits() int64   { return int64(s.diskHits) }
+func (s *CacheStats) GetMisses() int64     { return int64(s.misses) }
+func (s *CacheStats) GetMemoryItems() int  { return s.items }
+func (s *CacheStats) GetDiskItems() int64  { return int64(s.diskItems) }
+func (s *CacheStats) GetDiskBytes() int64  { return int64(s.diskBytes) }
@@ -2,6 +2,7 @@ package geth
 import (
 	"errors"
 	"github.com/Cryptochain-VON/p2p/discv5"
+	"github.com/Cryptochain-VON/p2p/enode"
 )
 type Enode struct {
 	node *discv5.Node
@@ -13,6 +14,9 @@ func NewEnode(rawurl string) (enode *Enode, _ error) {
 	}
 	return &Enode{node}, nil
 }
+func (e *Enode) v4() (*enode.Node, error) {
+	return enode.ParseV4(e.node.String())
+}
 type Enodes struct{ nodes []*discv5.Node }
 func NewEnodes(size int) *Enodes {
 	return &Enodes{
@@ -41,3 +45,14 @@ func (e *Enodes) Set(index int, enode *Enode) error {
 func (e *Enodes) Append(enode *Enode) {
 	e.nodes = append(e.nodes, enode.node)
 }
+func (e *Enodes) v4() ([]*enode.Node, error) {
+	nodes := make([]*enode.Node, 0, len(e.nodes))
+	for _, node := range e.nodes {
+		n, err := enode.ParseV4(node.String())
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes, nil
+}
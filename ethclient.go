@@ -3,40 +3,87 @@ import (
 	"math/big"
 	"github.com/Cryptochain-VON/core/types"
 	"github.com/Cryptochain-VON/ethclient"
+	"github.com/Cryptochain-VON/rpc"
 )
 type EthereumClient struct {
-	client *ethclient.Client
+	client    *ethclient.Client
+	rpc       *rpc.Client
+	resilient *reconnector
+	pool      *endpointPool
+	cache     *responseCache
 }
 func NewEthereumClient(rawurl string) (client *EthereumClient, _ error) {
-	rawClient, err := ethclient.Dial(rawurl)
-	return &EthereumClient{rawClient}, err
+	rawClient, err := rpc.Dial(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return newEthereumClient(rawClient), nil
+}
+func newEthereumClient(rawClient *rpc.Client) *EthereumClient {
+	return &EthereumClient{client: ethclient.NewClient(rawClient), rpc: rawClient}
 }
 func (ec *EthereumClient) GetBlockByHash(ctx *Context, hash *Hash) (block *Block, _ error) {
+	if rawBlock := ec.cache.block(hash.hash); rawBlock != nil {
+		return &Block{rawBlock}, nil
+	}
 	rawBlock, err := ec.client.BlockByHash(ctx.context, hash.hash)
+	if err == nil {
+		ec.cache.addBlock(rawBlock)
+	}
 	return &Block{rawBlock}, err
 }
 func (ec *EthereumClient) GetBlockByNumber(ctx *Context, number int64) (block *Block, _ error) {
 	if number < 0 {
 		rawBlock, err := ec.client.BlockByNumber(ctx.context, nil)
+		if err == nil {
+			ec.cache.observeHead(rawBlock.Header())
+		}
 		return &Block{rawBlock}, err
 	}
+	if rawBlock := ec.cache.blockByNumber(uint64(number)); rawBlock != nil {
+		return &Block{rawBlock}, nil
+	}
 	rawBlock, err := ec.client.BlockByNumber(ctx.context, big.NewInt(number))
+	if err == nil {
+		ec.cache.addBlock(rawBlock)
+	}
 	return &Block{rawBlock}, err
 }
 func (ec *EthereumClient) GetHeaderByHash(ctx *Context, hash *Hash) (header *Header, _ error) {
+	if rawHeader := ec.cache.header(hash.hash); rawHeader != nil {
+		return &Header{rawHeader}, nil
+	}
 	rawHeader, err := ec.client.HeaderByHash(ctx.context, hash.hash)
+	if err == nil {
+		ec.cache.addHeader(rawHeader)
+	}
 	return &Header{rawHeader}, err
 }
 func (ec *EthereumClient) GetHeaderByNumber(ctx *Context, number int64) (header *Header, _ error) {
 	if number < 0 {
 		rawHeader, err := ec.client.HeaderByNumber(ctx.context, nil)
+		if err == nil {
+			ec.cache.observeHead(rawHeader)
+		}
 		return &Header{rawHeader}, err
 	}
+	if rawHeader := ec.cache.headerByNumber(uint64(number)); rawHeader != nil {
+		return &Header{rawHeader}, nil
+	}
 	rawHeader, err := ec.client.HeaderByNumber(ctx.context, big.NewInt(number))
+	if err == nil {
+		ec.cache.addHeader(rawHeader)
+	}
 	return &Header{rawHeader}, err
 }
 func (ec *EthereumClient) GetTransactionByHash(ctx *Context, hash *Hash) (tx *Transaction, _ error) {
-	rawTx, _, err := ec.client.TransactionByHash(ctx.context, hash.hash)
+	if rawTx := ec.cache.transaction(hash.hash); rawTx != nil {
+		return &Transaction{rawTx}, nil
+	}
+	rawTx, pending, err := ec.client.TransactionByHash(ctx.context, hash.hash)
+	if err == nil && !pending {
+		ec.cache.addTransaction(rawTx)
+	}
 	return &Transaction{rawTx}, err
 }
 func (ec *EthereumClient) GetTransactionSender(ctx *Context, tx *Transaction, blockhash *Hash, index int) (sender *Address, _ error) {
@@ -52,7 +99,13 @@ func (ec *EthereumClient) GetTransactionInBlock(ctx *Context, hash *Hash, index
 	return &Transaction{rawTx}, err
 }
 func (ec *EthereumClient) GetTransactionReceipt(ctx *Context, hash *Hash) (receipt *Receipt, _ error) {
+	if rawReceipt := ec.cache.receipt(hash.hash); rawReceipt != nil {
+		return &Receipt{rawReceipt}, nil
+	}
 	rawReceipt, err := ec.client.TransactionReceipt(ctx.context, hash.hash)
+	if err == nil {
+		ec.cache.addReceipt(rawReceipt)
+	}
 	return &Receipt{rawReceipt}, err
 }
 func (ec *EthereumClient) SyncProgress(ctx *Context) (progress *SyncProgress, _ error) {
@@ -67,6 +120,9 @@ type NewHeadHandler interface {
 	OnError(failure string)
 }
 func (ec *EthereumClient) SubscribeNewHead(ctx *Context, handler NewHeadHandler, buffer int) (sub *Subscription, _ error) {
+	if ec.resilient != nil {
+		return ec.subscribeNewHeadResilient(ctx, handler, buffer)
+	}
 	ch := make(chan *types.Header, buffer)
 	rawSub, err := ec.client.SubscribeNewHead(ctx.context, ch)
 	if err != nil {
@@ -76,6 +132,7 @@ func (ec *EthereumClient) SubscribeNewHead(ctx *Context, handler NewHeadHandler,
 		for {
 			select {
 			case header := <-ch:
+				ec.cache.observeHead(header)
 				handler.OnNewHead(&Header{header})
 			case err := <-rawSub.Err():
 				if err != nil {
@@ -131,6 +188,9 @@ type FilterLogsHandler interface {
 	OnError(failure string)
 }
 func (ec *EthereumClient) SubscribeFilterLogs(ctx *Context, query *FilterQuery, handler FilterLogsHandler, buffer int) (sub *Subscription, _ error) {
+	if ec.resilient != nil {
+		return ec.subscribeFilterLogsResilient(ctx, query, handler, buffer)
+	}
 	ch := make(chan types.Log, buffer)
 	rawSub, err := ec.client.SubscribeFilterLogs(ctx.context, query.query, ch)
 	if err != nil {
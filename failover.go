@@ -0,0 +1,250 @@
+package geth
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+	"github.com/Cryptochain-VON/common/hexutil"
+	"github.com/Cryptochain-VON/rpc"
+)
+const (
+	endpointProbeInterval = 15 * time.Second
+	endpointProbeTimeout  = 5 * time.Second
+	endpointLagPenalty    = 1000
+	endpointErrorPenalty  = 2000
+)
+type endpoint struct {
+	raw      string
+	url      *url.URL
+	probe    *rpc.Client
+	latency  time.Duration
+	head     uint64
+	errRate  float64
+	requests uint64
+	failures uint64
+}
+type endpointPool struct {
+	endpoints []*endpoint
+	transport http.RoundTripper
+	lock      sync.RWMutex
+	quit      chan struct{}
+}
+func NewFailoverEthereumClient(endpoints *Strings) (client *EthereumClient, _ error) {
+	if endpoints == nil || endpoints.Size() == 0 {
+		return nil, errors.New("no endpoints")
+	}
+	pool := &endpointPool{
+		transport: http.DefaultTransport,
+		quit:      make(chan struct{}),
+	}
+	for _, raw := range endpoints.strs {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint %q: %v", raw, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("unsupported endpoint scheme %q, only http and https can fail over", u.Scheme)
+		}
+		probe, err := rpc.DialHTTP(raw)
+		if err != nil {
+			return nil, err
+		}
+		pool.endpoints = append(pool.endpoints, &endpoint{raw: raw, url: u, probe: probe})
+	}
+	rawClient, err := rpc.DialHTTPWithClient(endpoints.strs[0], &http.Client{Transport: pool})
+	if err != nil {
+		return nil, err
+	}
+	pool.probeAll()
+	go pool.loop()
+	client = newEthereumClient(rawClient)
+	client.pool = pool
+	return client, nil
+}
+func (ec *EthereumClient) Close() {
+	if ec.pool != nil {
+		ec.pool.close()
+	}
+	if ec.cache != nil {
+		ec.cache.close()
+	}
+	if ec.resilient != nil {
+		ec.resilient.close()
+	}
+	ec.rpc.Close()
+}
+func (ec *EthereumClient) GetEndpointScores() *EndpointScores {
+	if ec.pool == nil {
+		return &EndpointScores{}
+	}
+	return ec.pool.scores()
+}
+func (p *endpointPool) loop() {
+	ticker := time.NewTicker(endpointProbeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.probeAll()
+		case <-p.quit:
+			return
+		}
+	}
+}
+func (p *endpointPool) close() {
+	close(p.quit)
+	for _, ep := range p.endpoints {
+		ep.probe.Close()
+	}
+}
+func (p *endpointPool) probeAll() {
+	var wg sync.WaitGroup
+	for _, ep := range p.endpoints {
+		wg.Add(1)
+		go func(ep *endpoint) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), endpointProbeTimeout)
+			defer cancel()
+			var head hexutil.Uint64
+			start := time.Now()
+			err := ep.probe.CallContext(ctx, &head, "eth_blockNumber")
+			p.record(ep, time.Since(start), err)
+			if err == nil {
+				p.lock.Lock()
+				ep.head = uint64(head)
+				p.lock.Unlock()
+			}
+		}(ep)
+	}
+	wg.Wait()
+}
+func (p *endpointPool) record(ep *endpoint, latency time.Duration, err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	ep.requests++
+	ep.errRate *= 0.9
+	if err != nil {
+		ep.failures++
+		ep.errRate += 0.1
+		return
+	}
+	if ep.latency == 0 {
+		ep.latency = latency
+	} else {
+		ep.latency = (ep.latency*4 + latency) / 5
+	}
+}
+func (p *endpointPool) maxHead() uint64 {
+	var head uint64
+	for _, ep := range p.endpoints {
+		if ep.head > head {
+			head = ep.head
+		}
+	}
+	return head
+}
+func (p *endpointPool) score(ep *endpoint, head uint64) float64 {
+	latency := float64(ep.latency) / float64(time.Millisecond)
+	return latency + ep.errRate*endpointErrorPenalty + float64(head-ep.head)*endpointLagPenalty
+}
+func (p *endpointPool) ranked() []*endpoint {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	head := p.maxHead()
+	ranked := append([]*endpoint{}, p.endpoints...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return p.score(ranked[i], head) < p.score(ranked[j], head)
+	})
+	return ranked
+}
+func (p *endpointPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	var lastErr error
+	for _, ep := range p.ranked() {
+		r := req.Clone(req.Context())
+		r.URL = ep.url
+		r.Host = ep.url.Host
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		start := time.Now()
+		resp, err := p.transport.RoundTrip(r)
+		if err == nil && resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests {
+			p.record(ep, time.Since(start), nil)
+			return resp, nil
+		}
+		if err == nil {
+			resp.Body.Close()
+			err = fmt.Errorf("%s: %s", ep.raw, resp.Status)
+		}
+		p.record(ep, 0, err)
+		lastErr = err
+		if req.Context().Err() != nil {
+			break
+		}
+	}
+	return nil, lastErr
+}
+func (p *endpointPool) scores() *EndpointScores {
+	ranked := p.ranked()
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	head := p.maxHead()
+	scores := &EndpointScores{scores: make([]EndpointScore, len(ranked))}
+	for i, ep := range ranked {
+		scores.scores[i] = EndpointScore{
+			url:      ep.raw,
+			latency:  ep.latency,
+			head:     ep.head,
+			lag:      head - ep.head,
+			errRate:  ep.errRate,
+			requests: ep.requests,
+			failures: ep.failures,
+			score:    p.score(ep, head),
+			active:   i == 0,
+		}
+	}
+	return scores
+}
+type EndpointScore struct {
+	url      string
+	latency  time.Duration
+	head     uint64
+	lag      uint64
+	errRate  float64
+	requests uint64
+	failures uint64
+	score    float64
+	active   bool
+}
+func (s *EndpointScore) GetURL() string        { return s.url }
+func (s *EndpointScore) GetLatency() int64     { return int64(s.latency / time.Millisecond) }
+func (s *EndpointScore) GetHeadNumber() int64  { return int64(s.head) }
+func (s *EndpointScore) GetHeadLag() int64     { return int64(s.lag) }
+func (s *EndpointScore) GetErrorRate() float64 { return s.errRate }
+func (s *EndpointScore) GetRequests() int64    { return int64(s.requests) }
+func (s *EndpointScore) GetFailures() int64    { return int64(s.failures) }
+func (s *EndpointScore) GetScore() float64     { return s.score }
+func (s *EndpointScore) IsActive() bool        { return s.active }
+type EndpointScores struct{ scores []EndpointScore }
+func (s *EndpointScores) Size() int {
+	return len(s.scores)
+}
+func (s *EndpointScores) Get(index int) (score *EndpointScore, _ error) {
+	if index < 0 || index >= len(s.scores) {
+		return nil, errors.New("index out of bounds")
+	}
+	return &s.scores[index], nil
+}
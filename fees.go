@@ -0,0 +1,218 @@
+package geth
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sort"
+	ethereum "github.com/Cryptochain-VON"
+	"github.com/Cryptochain-VON/common/hexutil"
+	"github.com/Cryptochain-VON/rpc"
+)
+const (
+	defaultFeeHistoryBlocks = 20
+	maxFeeHistoryBlocks     = 1024
+)
+type FeeHistory struct {
+	oldest       *big.Int
+	reward       [][]*big.Int
+	baseFee      []*big.Int
+	gasUsedRatio []float64
+}
+type feeHistoryResult struct {
+	OldestBlock  *hexutil.Big     `json:"oldestBlock"`
+	Reward       [][]*hexutil.Big `json:"reward,omitempty"`
+	BaseFee      []*hexutil.Big   `json:"baseFeePerGas,omitempty"`
+	GasUsedRatio []float64        `json:"gasUsedRatio"`
+}
+func (ec *EthereumClient) SuggestGasTipCap(ctx *Context) (tip *BigInt, _ error) {
+	var hex hexutil.Big
+	if err := ec.rpc.CallContext(ctx.context, &hex, "eth_maxPriorityFeePerGas"); err != nil {
+		return nil, err
+	}
+	return &BigInt{(*big.Int)(&hex)}, nil
+}
+func (ec *EthereumClient) GetBaseFeeAt(ctx *Context, number int64) (fee *BigInt, _ error) {
+	var block struct {
+		BaseFee *hexutil.Big `json:"baseFeePerGas"`
+	}
+	var raw json.RawMessage
+	if err := ec.rpc.CallContext(ctx.context, &raw, "eth_getBlockByNumber", toBlockNumArg(number), false); err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, ethereum.NotFound
+	}
+	if err := json.Unmarshal(raw, &block); err != nil {
+		return nil, err
+	}
+	if block.BaseFee == nil {
+		return nil, errors.New("block has no base fee")
+	}
+	return &BigInt{(*big.Int)(block.BaseFee)}, nil
+}
+func (ec *EthereumClient) FeeHistory(ctx *Context, blockCount int, newest int64, percentiles *Floats) (history *FeeHistory, _ error) {
+	if blockCount < 1 || blockCount > maxFeeHistoryBlocks {
+		return nil, errors.New("block count out of range")
+	}
+	var rewardPercentiles []float64
+	if percentiles != nil {
+		rewardPercentiles = percentiles.floats
+	}
+	var res feeHistoryResult
+	if err := ec.rpc.CallContext(ctx.context, &res, "eth_feeHistory", hexutil.Uint(blockCount), toBlockNumArg(newest), rewardPercentiles); err != nil {
+		return nil, err
+	}
+	if res.OldestBlock == nil {
+		return nil, errors.New("fee history not available")
+	}
+	history = &FeeHistory{
+		oldest:       (*big.Int)(res.OldestBlock),
+		reward:       make([][]*big.Int, len(res.Reward)),
+		baseFee:      make([]*big.Int, len(res.BaseFee)),
+		gasUsedRatio: res.GasUsedRatio,
+	}
+	for i, rewards := range res.Reward {
+		history.reward[i] = make([]*big.Int, len(rewards))
+		for j, reward := range rewards {
+			history.reward[i][j] = (*big.Int)(reward)
+		}
+	}
+	for i, fee := range res.BaseFee {
+		history.baseFee[i] = (*big.Int)(fee)
+	}
+	return history, nil
+}
+func (fh *FeeHistory) GetOldestBlock() int64 { return fh.oldest.Int64() }
+func (fh *FeeHistory) GetBlockCount() int    { return len(fh.gasUsedRatio) }
+func (fh *FeeHistory) GetBaseFee(index int) (fee *BigInt, _ error) {
+	if index < 0 || index >= len(fh.baseFee) {
+		return nil, errors.New("index out of bounds")
+	}
+	return &BigInt{fh.baseFee[index]}, nil
+}
+func (fh *FeeHistory) GetNextBaseFee() *BigInt {
+	if len(fh.baseFee) == 0 {
+		return nil
+	}
+	return &BigInt{fh.baseFee[len(fh.baseFee)-1]}
+}
+func (fh *FeeHistory) GetGasUsedRatio(index int) (ratio float64, _ error) {
+	if index < 0 || index >= len(fh.gasUsedRatio) {
+		return 0, errors.New("index out of bounds")
+	}
+	return fh.gasUsedRatio[index], nil
+}
+func (fh *FeeHistory) GetReward(index int, percentile int) (reward *BigInt, _ error) {
+	if index < 0 || index >= len(fh.reward) || percentile < 0 || percentile >= len(fh.reward[index]) {
+		return nil, errors.New("index out of bounds")
+	}
+	return &BigInt{fh.reward[index][percentile]}, nil
+}
+type FeePreset struct {
+	maxFee *big.Int
+	tipCap *big.Int
+}
+func (fp *FeePreset) GetMaxFeePerGas() *BigInt         { return &BigInt{fp.maxFee} }
+func (fp *FeePreset) GetMaxPriorityFeePerGas() *BigInt { return &BigInt{fp.tipCap} }
+type FeeEstimates struct {
+	slow    *FeePreset
+	normal  *FeePreset
+	fast    *FeePreset
+	baseFee *big.Int
+}
+func (fe *FeeEstimates) GetSlow() *FeePreset   { return fe.slow }
+func (fe *FeeEstimates) GetNormal() *FeePreset { return fe.normal }
+func (fe *FeeEstimates) GetFast() *FeePreset   { return fe.fast }
+func (fe *FeeEstimates) IsLegacy() bool        { return fe.baseFee == nil }
+func (fe *FeeEstimates) GetBaseFee() *BigInt {
+	if fe.baseFee == nil {
+		return nil
+	}
+	return &BigInt{fe.baseFee}
+}
+type FeeEstimator struct {
+	client *EthereumClient
+	blocks int
+}
+func NewFeeEstimator(client *EthereumClient) *FeeEstimator {
+	return &FeeEstimator{
+		client: client,
+		blocks: defaultFeeHistoryBlocks,
+	}
+}
+func (fe *FeeEstimator) SetBlockCount(blocks int) error {
+	if blocks < 1 || blocks > maxFeeHistoryBlocks {
+		return errors.New("block count out of range")
+	}
+	fe.blocks = blocks
+	return nil
+}
+func (fe *FeeEstimator) Estimate(ctx *Context) (estimates *FeeEstimates, _ error) {
+	percentiles := &Floats{[]float64{10, 50, 90}}
+	history, err := fe.client.FeeHistory(ctx, fe.blocks, -1, percentiles)
+	if err != nil && !isMethodNotFound(err) {
+		return nil, err
+	}
+	if err != nil || history.GetNextBaseFee() == nil {
+		return fe.estimateLegacy(ctx)
+	}
+	baseFee := history.baseFee[len(history.baseFee)-1]
+	presets := make([]*FeePreset, len(percentiles.floats))
+	for i := range presets {
+		tip := history.medianReward(i)
+		if tip == nil {
+			suggested, err := fe.client.SuggestGasTipCap(ctx)
+			if err != nil {
+				return nil, err
+			}
+			tip = suggested.bigint
+		}
+		maxFee := new(big.Int).Mul(baseFee, big.NewInt(2))
+		presets[i] = &FeePreset{
+			maxFee: maxFee.Add(maxFee, tip),
+			tipCap: tip,
+		}
+	}
+	return &FeeEstimates{
+		slow:    presets[0],
+		normal:  presets[1],
+		fast:    presets[2],
+		baseFee: baseFee,
+	}, nil
+}
+func (fe *FeeEstimator) estimateLegacy(ctx *Context) (*FeeEstimates, error) {
+	price, err := fe.client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	scale := func(num int64) *FeePreset {
+		fee := new(big.Int).Mul(price.bigint, big.NewInt(num))
+		fee.Div(fee, big.NewInt(100))
+		return &FeePreset{maxFee: fee, tipCap: fee}
+	}
+	return &FeeEstimates{
+		slow:   scale(90),
+		normal: scale(100),
+		fast:   scale(125),
+	}, nil
+}
+func (fh *FeeHistory) medianReward(percentile int) *big.Int {
+	var samples []*big.Int
+	for i, rewards := range fh.reward {
+		if i < len(fh.gasUsedRatio) && fh.gasUsedRatio[i] == 0 {
+			continue
+		}
+		if percentile < len(rewards) && rewards[percentile] != nil {
+			samples = append(samples, rewards[percentile])
+		}
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Cmp(samples[j]) < 0 })
+	return new(big.Int).Set(samples[len(samples)/2])
+}
+func isMethodNotFound(err error) bool {
+	rpcErr, ok := err.(rpc.Error)
+	return ok && rpcErr.ErrorCode() == -32601
+}
@@ -0,0 +1,139 @@
+package geth
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"github.com/Cryptochain-VON/common"
+	"github.com/Cryptochain-VON/core/types"
+)
+const defaultFollowerDepth = 64
+type ChainFollowerHandler interface {
+	OnNewHead(header *Header)
+	OnReorg(oldHeaders *Headers, newHeaders *Headers)
+	OnError(failure string)
+}
+type ChainFollower struct {
+	client  *EthereumClient
+	handler ChainFollowerHandler
+	depth   int
+	window  []*types.Header
+	sub     *Subscription
+	lock    sync.Mutex
+}
+func NewChainFollower(client *EthereumClient, handler ChainFollowerHandler, depth int) *ChainFollower {
+	if depth <= 0 {
+		depth = defaultFollowerDepth
+	}
+	return &ChainFollower{
+		client:  client,
+		handler: handler,
+		depth:   depth,
+	}
+}
+func (f *ChainFollower) Start(ctx *Context, buffer int) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.sub != nil {
+		return errors.New("follower already started")
+	}
+	sub, err := f.client.SubscribeNewHead(ctx, &followerHeadHandler{f, ctx}, buffer)
+	if err != nil {
+		return err
+	}
+	f.sub = sub
+	return nil
+}
+func (f *ChainFollower) Stop() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.sub != nil {
+		f.sub.Unsubscribe()
+		f.sub = nil
+	}
+}
+func (f *ChainFollower) GetHeaders() *Headers {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return &Headers{append([]*types.Header{}, f.window...)}
+}
+type followerHeadHandler struct {
+	follower *ChainFollower
+	ctx      *Context
+}
+func (h *followerHeadHandler) OnNewHead(header *Header) { h.follower.process(h.ctx, header.header) }
+func (h *followerHeadHandler) OnError(failure string)   { h.follower.handler.OnError(failure) }
+func (f *ChainFollower) process(ctx *Context, header *types.Header) {
+	f.lock.Lock()
+	window := append([]*types.Header{}, f.window...)
+	f.lock.Unlock()
+	window, dropped, added, err := f.insert(ctx, window, header)
+	if err != nil {
+		f.handler.OnError(err.Error())
+		return
+	}
+	f.lock.Lock()
+	f.window = window
+	f.lock.Unlock()
+	if len(dropped) > 0 {
+		f.handler.OnReorg(&Headers{dropped}, &Headers{added})
+	}
+	for _, header := range added {
+		f.handler.OnNewHead(&Header{header})
+	}
+}
+func (f *ChainFollower) insert(ctx *Context, window []*types.Header, header *types.Header) (_ []*types.Header, dropped []*types.Header, added []*types.Header, _ error) {
+	if len(window) == 0 {
+		return []*types.Header{header}, nil, []*types.Header{header}, nil
+	}
+	tip := window[len(window)-1]
+	if tip.Hash() == header.Hash() {
+		return window, nil, nil, nil
+	}
+	if new(big.Int).Sub(header.Number, tip.Number).Cmp(big.NewInt(int64(f.depth))) > 0 {
+		return f.resync(ctx, window, header)
+	}
+	added = []*types.Header{header}
+	for {
+		first := added[0]
+		if index := findHeader(window, first.ParentHash); index >= 0 {
+			dropped = window[index+1:]
+			window = append(window[:index+1:index+1], added...)
+			break
+		}
+		if first.Number.Cmp(window[0].Number) <= 0 || first.Number.Sign() == 0 {
+			dropped, window = window, added
+			break
+		}
+		parent, err := f.client.client.HeaderByHash(ctx.context, first.ParentHash)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		added = append([]*types.Header{parent}, added...)
+	}
+	if len(window) > f.depth {
+		window = window[len(window)-f.depth:]
+	}
+	return window, dropped, added, nil
+}
+func (f *ChainFollower) resync(ctx *Context, window []*types.Header, header *types.Header) (_ []*types.Header, dropped []*types.Header, added []*types.Header, _ error) {
+	for i := len(window) - 1; i >= 0; i-- {
+		canonical, err := f.client.client.HeaderByNumber(ctx.context, window[i].Number)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if canonical.Hash() == window[i].Hash() {
+			break
+		}
+		dropped = append([]*types.Header{window[i]}, dropped...)
+		added = append([]*types.Header{canonical}, added...)
+	}
+	return []*types.Header{header}, dropped, append(added, header), nil
+}
+func findHeader(window []*types.Header, hash common.Hash) int {
+	for i := len(window) - 1; i >= 0; i-- {
+		if window[i].Hash() == hash {
+			return i
+		}
+	}
+	return -1
+}
@@ -1,45 +1,120 @@
 package geth
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"strings"
+	"sync"
+	"github.com/Cryptochain-VON/common"
+	"github.com/Cryptochain-VON/common/hexutil"
 	"github.com/Cryptochain-VON/core"
 	"github.com/Cryptochain-VON/eth"
 	"github.com/Cryptochain-VON/eth/downloader"
-	"github.com/Cryptochain-VON/ethclient"
 	"github.com/Cryptochain-VON/ethstats"
+	"github.com/Cryptochain-VON/event"
 	"github.com/Cryptochain-VON/internal/debug"
 	"github.com/Cryptochain-VON/les"
 	"github.com/Cryptochain-VON/node"
 	"github.com/Cryptochain-VON/p2p"
 	"github.com/Cryptochain-VON/p2p/nat"
+	"github.com/Cryptochain-VON/p2p/netutil"
 	"github.com/Cryptochain-VON/params"
+	"github.com/Cryptochain-VON/rpc"
 	whisper "github.com/Cryptochain-VON/whisper/whisperv6"
 )
 type NodeConfig struct {
 	BootstrapNodes *Enodes
+	StaticNodes *Enodes
+	TrustedNodes *Enodes
 	MaxPeers int
+	ListenAddr string
+	DiscoveryV4 bool
+	DiscoveryV5 bool
+	NAT string
+	NetRestrict string
+	PeerTrafficStats bool
 	EthereumEnabled bool
 	EthereumNetworkID int64 
 	EthereumGenesis string
+	EthereumNetwork *NetworkPreset
+	EthereumCheckpoint *Checkpoint
+	EthereumCheckpointOracle *CheckpointOracle
+	SyncMode string
 	EthereumDatabaseCache int
 	EthereumNetStats string
+	EthereumLightServ int
+	EthereumLightPeers int
+	EthereumLightIngress int
+	EthereumLightEgress int
 	WhisperEnabled bool
 	PprofAddress string
+	BackgroundProfile *PowerProfile
+	HTTPHost string
+	HTTPPort int
+	HTTPCors string
+	HTTPVirtualHosts string
+	HTTPModules string
+	WSHost string
+	WSPort int
+	WSOrigins string
+	WSModules string
+	IPCPath string
 }
+const (
+	LightSyncMode = "light"
+	FastSyncMode  = "fast"
+	FullSyncMode  = "full"
+)
 var defaultNodeConfig = &NodeConfig{
 	BootstrapNodes:        FoundationBootnodes(),
 	MaxPeers:              25,
+	ListenAddr:            ":0",
+	DiscoveryV5:           true,
+	NAT:                   "any",
+	HTTPPort:              node.DefaultHTTPPort,
+	HTTPVirtualHosts:      "localhost",
+	HTTPModules:           "net,web3,eth",
+	WSPort:                node.DefaultWSPort,
+	WSModules:             "net,web3,eth",
 	EthereumEnabled:       true,
 	EthereumNetworkID:     1,
 	EthereumDatabaseCache: 16,
+	EthereumLightPeers:    eth.DefaultConfig.LightPeers,
+	SyncMode:              LightSyncMode,
 }
 func NewNodeConfig() *NodeConfig {
 	config := *defaultNodeConfig
 	return &config
 }
+const (
+	NodeStatusCreated  = "created"
+	NodeStatusStarting = "starting"
+	NodeStatusRunning  = "running"
+	NodeStatusStopping = "stopping"
+	NodeStatusStopped  = "stopped"
+	NodeStatusClosed   = "closed"
+)
+var errNodeClosed = errors.New("node closed")
+type NodeStatusHandler interface {
+	OnStatusChanged(status string)
+}
 type Node struct {
-	node *node.Node
+	node        *node.Node
+	config      NodeConfig
+	nodesDir    string
+	dialer      *gatedDialer
+	traffic     *trafficTracker
+	peerRelay   event.Subscription
+	peerFeed    event.Feed
+	peerScope   event.SubscriptionScope
+	bg          *backgroundState
+	status      string
+	transitions []string
+	notifying   bool
+	handler     NodeStatusHandler
+	lock        sync.Mutex
+	lifecycle   sync.Mutex
 }
 func NewNode(datadir string, config *NodeConfig) (stack *Node, _ error) {
 	if config == nil {
@@ -48,24 +123,135 @@ func NewNode(datadir string, config *NodeConfig) (stack *Node, _ error) {
 	if config.MaxPeers == 0 {
 		config.MaxPeers = defaultNodeConfig.MaxPeers
 	}
+	var genesis *core.Genesis
+	if config.EthereumGenesis != "" {
+		genesis = new(core.Genesis)
+		if err := json.Unmarshal([]byte(config.EthereumGenesis), genesis); err != nil {
+			return nil, fmt.Errorf("invalid genesis spec: %v", err)
+		}
+	}
+	preset := config.EthereumNetwork
+	if genesis != nil {
+		hash := genesis.ToBlock(nil).Hash()
+		if preset == nil {
+			preset = findNetworkPreset(hash)
+		} else if preset.hash != hash {
+			return nil, fmt.Errorf("genesis %x does not match network preset %q (%x)", hash, preset.name, preset.hash)
+		}
+	}
+	if preset != nil {
+		if genesis == nil && preset.genesis != nil {
+			spec := *preset.genesis
+			genesis = &spec
+		}
+		if genesis != nil && preset.config != nil {
+			genesis.Config = preset.config
+		}
+		if config.EthereumNetworkID == 1 {
+			config.EthereumNetworkID = preset.networkID
+		}
+		if preset.bootnodes != nil && preset.bootnodes.Size() > 0 {
+			if config.BootstrapNodes == nil || config.BootstrapNodes.Size() == 0 || config.BootstrapNodes == defaultNodeConfig.BootstrapNodes {
+				config.BootstrapNodes = preset.bootnodes
+			}
+		}
+	}
 	if config.BootstrapNodes == nil || config.BootstrapNodes.Size() == 0 {
 		config.BootstrapNodes = defaultNodeConfig.BootstrapNodes
 	}
+	if config.SyncMode == "" {
+		config.SyncMode = defaultNodeConfig.SyncMode
+	}
+	var syncMode downloader.SyncMode
+	if err := syncMode.UnmarshalText([]byte(config.SyncMode)); err != nil {
+		return nil, fmt.Errorf("unsupported sync mode %q: %v", config.SyncMode, err)
+	}
+	if config.EthereumLightServ < 0 {
+		return nil, fmt.Errorf("invalid light serving percentage: %d", config.EthereumLightServ)
+	}
+	if config.EthereumLightServ > 0 && syncMode == downloader.LightSync {
+		return nil, fmt.Errorf("light serving requires %s or %s sync mode", FastSyncMode, FullSyncMode)
+	}
+	if config.EthereumLightPeers == 0 {
+		config.EthereumLightPeers = defaultNodeConfig.EthereumLightPeers
+	}
+	if config.BackgroundProfile == nil {
+		config.BackgroundProfile = NewPowerProfile()
+	}
+	if config.BackgroundProfile.MaxPeers < 0 {
+		return nil, fmt.Errorf("invalid background peer limit: %d", config.BackgroundProfile.MaxPeers)
+	}
+	if config.HTTPPort == 0 {
+		config.HTTPPort = defaultNodeConfig.HTTPPort
+	}
+	if config.WSPort == 0 {
+		config.WSPort = defaultNodeConfig.WSPort
+	}
+	if config.ListenAddr == "" {
+		config.ListenAddr = defaultNodeConfig.ListenAddr
+	}
+	if config.NAT == "" {
+		config.NAT = defaultNodeConfig.NAT
+	}
+	natif, err := nat.Parse(config.NAT)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NAT spec %q: %v", config.NAT, err)
+	}
+	var netrestrict *netutil.Netlist
+	if config.NetRestrict != "" {
+		if netrestrict, err = netutil.ParseNetlist(config.NetRestrict); err != nil {
+			return nil, fmt.Errorf("invalid netrestrict list %q: %v", config.NetRestrict, err)
+		}
+	}
+	bootnodes, err := config.BootstrapNodes.v4()
+	if err != nil {
+		return nil, fmt.Errorf("invalid bootstrap node: %v", err)
+	}
+	var nodesDir string
+	if datadir != "" {
+		nodesDir = filepath.Join(datadir, clientIdentifier)
+	}
+	staticNodes, err := loadPersistentNodes(config.StaticNodes, nodesDir, staticNodesFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid static node: %v", err)
+	}
+	trustedNodes, err := loadPersistentNodes(config.TrustedNodes, nodesDir, trustedNodesFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted node: %v", err)
+	}
 	if config.PprofAddress != "" {
 		debug.StartPProf(config.PprofAddress)
 	}
+	dialer := newGatedDialer(staticNodes, trustedNodes)
 	nodeConf := &node.Config{
-		Name:        clientIdentifier,
-		Version:     params.VersionWithMeta,
-		DataDir:     datadir,
-		KeyStoreDir: filepath.Join(datadir, "keystore"), 
+		Name:             clientIdentifier,
+		Version:          params.VersionWithMeta,
+		DataDir:          datadir,
+		KeyStoreDir:      filepath.Join(datadir, "keystore"),
+		HTTPHost:         config.HTTPHost,
+		HTTPPort:         config.HTTPPort,
+		HTTPCors:         splitAndTrim(config.HTTPCors),
+		HTTPVirtualHosts: splitAndTrim(config.HTTPVirtualHosts),
+		HTTPModules:      splitAndTrim(config.HTTPModules),
+		HTTPTimeouts:     rpc.DefaultHTTPTimeouts,
+		WSHost:           config.WSHost,
+		WSPort:           config.WSPort,
+		WSOrigins:        splitAndTrim(config.WSOrigins),
+		WSModules:        splitAndTrim(config.WSModules),
+		IPCPath:          config.IPCPath,
 		P2P: p2p.Config{
-			NoDiscovery:      true,
-			DiscoveryV5:      true,
+			NoDiscovery:      !config.DiscoveryV4,
+			DiscoveryV5:      config.DiscoveryV5,
+			BootstrapNodes:   bootnodes,
 			BootstrapNodesV5: config.BootstrapNodes.nodes,
-			ListenAddr:       ":0",
-			NAT:              nat.Any(),
+			StaticNodes:      staticNodes,
+			TrustedNodes:     trustedNodes,
+			Dialer:           dialer,
+			ListenAddr:       config.ListenAddr,
+			NAT:              natif,
+			NetRestrict:      netrestrict,
 			MaxPeers:         config.MaxPeers,
+			EnableMsgEvents:  config.PeerTrafficStats,
 		},
 	}
 	rawStack, err := node.New(nodeConf)
@@ -73,47 +259,50 @@ func NewNode(datadir string, config *NodeConfig) (stack *Node, _ error) {
 		return nil, err
 	}
 	debug.Memsize.Add("node", rawStack)
-	var genesis *core.Genesis
-	if config.EthereumGenesis != "" {
-		genesis = new(core.Genesis)
-		if err := json.Unmarshal([]byte(config.EthereumGenesis), genesis); err != nil {
-			return nil, fmt.Errorf("invalid genesis spec: %v", err)
-		}
-		if config.EthereumGenesis == RopstenGenesis() {
-			genesis.Config = params.RopstenChainConfig
-			if config.EthereumNetworkID == 1 {
-				config.EthereumNetworkID = 3
-			}
-		}
-		if config.EthereumGenesis == RinkebyGenesis() {
-			genesis.Config = params.RinkebyChainConfig
-			if config.EthereumNetworkID == 1 {
-				config.EthereumNetworkID = 4
-			}
-		}
-		if config.EthereumGenesis == GoerliGenesis() {
-			genesis.Config = params.GoerliChainConfig
-			if config.EthereumNetworkID == 1 {
-				config.EthereumNetworkID = 5
-			}
-		}
-	}
 	if config.EthereumEnabled {
 		ethConf := eth.DefaultConfig
 		ethConf.Genesis = genesis
-		ethConf.SyncMode = downloader.LightSync
+		ethConf.SyncMode = syncMode
+		if preset != nil {
+			ethConf.Checkpoint = preset.checkpoint
+		}
+		if config.EthereumCheckpoint != nil {
+			checkpoint := config.EthereumCheckpoint.checkpoint
+			ethConf.Checkpoint = &checkpoint
+		}
+		if config.EthereumCheckpointOracle != nil {
+			oracle := config.EthereumCheckpointOracle.oracle
+			ethConf.CheckpointOracle = &oracle
+		}
 		ethConf.NetworkId = uint64(config.EthereumNetworkID)
 		ethConf.DatabaseCache = config.EthereumDatabaseCache
+		ethConf.LightServ = config.EthereumLightServ
+		ethConf.LightPeers = config.EthereumLightPeers
+		ethConf.LightIngress = config.EthereumLightIngress
+		ethConf.LightEgress = config.EthereumLightEgress
 		if err := rawStack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
-			return les.New(ctx, &ethConf)
+			if syncMode == downloader.LightSync {
+				return les.New(ctx, &ethConf)
+			}
+			fullNode, err := eth.New(ctx, &ethConf)
+			if fullNode != nil && ethConf.LightServ > 0 {
+				lesServer, err := les.NewLesServer(fullNode, &ethConf)
+				if err != nil {
+					return nil, err
+				}
+				fullNode.AddLesServer(lesServer)
+			}
+			return fullNode, err
 		}); err != nil {
 			return nil, fmt.Errorf("ethereum init: %v", err)
 		}
 		if config.EthereumNetStats != "" {
 			if err := rawStack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+				var ethServ *eth.Ethereum
+				ctx.Service(&ethServ)
 				var lesServ *les.LightEthereum
 				ctx.Service(&lesServ)
-				return ethstats.New(config.EthereumNetStats, nil, lesServ)
+				return ethstats.New(config.EthereumNetStats, ethServ, lesServ)
 			}); err != nil {
 				return nil, fmt.Errorf("netstats init: %v", err)
 			}
@@ -126,27 +315,214 @@ func NewNode(datadir string, config *NodeConfig) (stack *Node, _ error) {
 			return nil, fmt.Errorf("whisper init: %v", err)
 		}
 	}
-	return &Node{rawStack}, nil
+	return &Node{node: rawStack, config: *config, nodesDir: nodesDir, dialer: dialer, status: NodeStatusCreated}, nil
+}
+func (n *Node) GetStatus() string {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.status
+}
+func (n *Node) SetStatusHandler(handler NodeStatusHandler) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.handler = handler
+}
+func (n *Node) setStatus(status string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.status = status
+	n.transitions = append(n.transitions, status)
+}
+func (n *Node) notifyStatus() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if n.notifying {
+		return
+	}
+	n.notifying = true
+	for len(n.transitions) > 0 {
+		status, handler := n.transitions[0], n.handler
+		n.transitions = n.transitions[1:]
+		if handler != nil {
+			n.lock.Unlock()
+			handler.OnStatusChanged(status)
+			n.lock.Lock()
+		}
+	}
+	n.notifying = false
 }
 func (n *Node) Close() error {
-	return n.node.Close()
+	n.lifecycle.Lock()
+	defer n.notifyStatus()
+	defer n.lifecycle.Unlock()
+	if n.GetStatus() == NodeStatusClosed {
+		return nil
+	}
+	if err := n.stop(); err != nil {
+		return err
+	}
+	if err := n.node.Close(); err != nil {
+		return err
+	}
+	n.peerScope.Close()
+	n.setStatus(NodeStatusClosed)
+	return nil
 }
 func (n *Node) Start() error {
-	return n.node.Start()
+	n.lifecycle.Lock()
+	defer n.notifyStatus()
+	defer n.lifecycle.Unlock()
+	prev := n.GetStatus()
+	switch prev {
+	case NodeStatusRunning:
+		return nil
+	case NodeStatusClosed:
+		return errNodeClosed
+	}
+	n.setStatus(NodeStatusStarting)
+	if err := n.node.Start(); err != nil {
+		n.setStatus(prev)
+		return err
+	}
+	n.lock.Lock()
+	if n.config.PeerTrafficStats {
+		n.traffic = newTrafficTracker(n.node.Server())
+	}
+	n.peerRelay = n.relayPeerEvents(n.node.Server())
+	n.lock.Unlock()
+	n.setStatus(NodeStatusRunning)
+	return nil
 }
 func (n *Node) Stop() error {
-	return n.node.Stop()
+	n.lifecycle.Lock()
+	defer n.notifyStatus()
+	defer n.lifecycle.Unlock()
+	return n.stop()
+}
+func (n *Node) stop() error {
+	switch n.GetStatus() {
+	case NodeStatusCreated, NodeStatusStopped, NodeStatusClosed:
+		return nil
+	}
+	n.setStatus(NodeStatusStopping)
+	n.enterForeground()
+	if err := n.node.Stop(); err != nil {
+		n.setStatus(NodeStatusRunning)
+		return err
+	}
+	n.lock.Lock()
+	if n.traffic != nil {
+		n.traffic.stop()
+		n.traffic = nil
+	}
+	if n.peerRelay != nil {
+		n.peerRelay.Unsubscribe()
+		n.peerRelay = nil
+	}
+	n.lock.Unlock()
+	n.setStatus(NodeStatusStopped)
+	return nil
 }
 func (n *Node) GetEthereumClient() (client *EthereumClient, _ error) {
 	rpc, err := n.node.Attach()
 	if err != nil {
 		return nil, err
 	}
-	return &EthereumClient{ethclient.NewClient(rpc)}, nil
+	return newEthereumClient(rpc), nil
 }
 func (n *Node) GetNodeInfo() *NodeInfo {
 	return &NodeInfo{n.node.Server().NodeInfo()}
 }
 func (n *Node) GetPeersInfo() *PeerInfos {
-	return &PeerInfos{n.node.Server().PeersInfo()}
+	n.lock.Lock()
+	traffic := n.traffic
+	n.lock.Unlock()
+	return &PeerInfos{infos: n.node.Server().PeersInfo(), traffic: traffic.snapshot()}
+}
+func (n *Node) GetLightServerStats(ctx *Context) (stats *LightServerStats, _ error) {
+	if n.config.EthereumLightServ == 0 {
+		return nil, errors.New("light serving disabled")
+	}
+	server := n.node.Server()
+	if server == nil {
+		return nil, errors.New("node not started")
+	}
+	stats = &LightServerStats{
+		serv:    n.config.EthereumLightServ,
+		peers:   n.config.EthereumLightPeers,
+		ingress: n.config.EthereumLightIngress,
+		egress:  n.config.EthereumLightEgress,
+	}
+	for _, info := range server.PeersInfo() {
+		if _, ok := info.Protocols["les"]; ok && info.Network.Inbound {
+			stats.clients++
+		}
+	}
+	client, err := n.node.Attach()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	if err := client.CallContext(ctx.context, &stats.info, "les_serverInfo"); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+func (n *Node) GetCheckpointStatus(ctx *Context) (status *CheckpointStatus, _ error) {
+	client, err := n.node.Attach()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	var latest [4]string
+	if err := client.CallContext(ctx.context, &latest, "les_latestCheckpoint"); err != nil {
+		return nil, err
+	}
+	index, err := hexutil.DecodeUint64(latest[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid checkpoint index: %v", err)
+	}
+	status = &CheckpointStatus{
+		checkpoint: &Checkpoint{params.TrustedCheckpoint{
+			SectionIndex: index,
+			SectionHead:  common.HexToHash(latest[1]),
+			CHTRoot:      common.HexToHash(latest[2]),
+			BloomRoot:    common.HexToHash(latest[3]),
+		}},
+	}
+	var oracle string
+	if err := client.CallContext(ctx.context, &oracle, "les_getCheckpointContractAddress"); err == nil && common.IsHexAddress(oracle) {
+		status.oracle = &Address{common.HexToAddress(oracle)}
+	}
+	return status, nil
+}
+func (n *Node) GetRPCEndpoints() *RPCEndpoints {
+	endpoints := new(RPCEndpoints)
+	if n.config.HTTPHost != "" {
+		endpoints.http = "http://" + n.node.HTTPEndpoint()
+	}
+	if n.config.WSHost != "" {
+		endpoints.ws = "ws://" + n.node.WSEndpoint()
+	}
+	if n.config.IPCPath != "" {
+		endpoints.ipc = n.node.IPCEndpoint()
+	}
+	return endpoints
+}
+type RPCEndpoints struct {
+	http string
+	ws   string
+	ipc  string
+}
+func (e *RPCEndpoints) GetHTTP() string { return e.http }
+func (e *RPCEndpoints) GetWS() string   { return e.ws }
+func (e *RPCEndpoints) GetIPC() string  { return e.ipc }
+func splitAndTrim(input string) []string {
+	var result []string
+	for _, r := range strings.Split(input, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			result = append(result, r)
+		}
+	}
+	return result
 }
@@ -0,0 +1,212 @@
+package geth
+import (
+	"math/big"
+	ethereum "github.com/Cryptochain-VON"
+	"github.com/Cryptochain-VON/common"
+	"github.com/Cryptochain-VON/core/types"
+	"github.com/Cryptochain-VON/event"
+)
+const (
+	logStreamChunk       = 2000
+	logStreamReorgWindow = 128
+)
+type LogStreamHandler interface {
+	OnLog(log *Log)
+	OnHistoryComplete(head int64)
+	OnError(failure string)
+}
+type logKey struct {
+	block common.Hash
+	index uint
+}
+type logDeduper struct {
+	seen map[logKey]uint64
+	head uint64
+}
+func (d *logDeduper) admit(log *types.Log) bool {
+	key := logKey{log.BlockHash, log.Index}
+	if log.Removed {
+		if _, ok := d.seen[key]; !ok {
+			return false
+		}
+		delete(d.seen, key)
+		return true
+	}
+	if _, ok := d.seen[key]; ok {
+		return false
+	}
+	d.seen[key] = log.BlockNumber
+	if log.BlockNumber > d.head {
+		d.head = log.BlockNumber
+		for key, number := range d.seen {
+			if number+logStreamReorgWindow < d.head {
+				delete(d.seen, key)
+			}
+		}
+	}
+	return true
+}
+func (ec *EthereumClient) StreamLogs(ctx *Context, query *FilterQuery, handler LogStreamHandler) (sub *Subscription, _ error) {
+	if query.query.BlockHash != nil {
+		return ec.streamBlockLogs(ctx, query, handler)
+	}
+	live := query.query
+	live.FromBlock, live.ToBlock, live.BlockHash = nil, nil, nil
+	ch := make(chan types.Log, 128)
+	rawSub, err := ec.client.SubscribeFilterLogs(ctx.context, live, ch)
+	if err != nil {
+		return nil, err
+	}
+	head, err := ec.client.HeaderByNumber(ctx.context, nil)
+	if err != nil {
+		rawSub.Unsubscribe()
+		return nil, err
+	}
+	var (
+		lower, upper uint64
+		bounded      = query.query.ToBlock != nil && query.query.ToBlock.Sign() >= 0
+		heads        chan *types.Header
+		headSub      ethereum.Subscription
+	)
+	if query.query.FromBlock != nil && query.query.FromBlock.Sign() >= 0 {
+		lower = query.query.FromBlock.Uint64()
+	}
+	if bounded {
+		upper = query.query.ToBlock.Uint64()
+		if upper > head.Number.Uint64() {
+			heads = make(chan *types.Header, 16)
+			if headSub, err = ec.client.SubscribeNewHead(ctx.context, heads); err != nil {
+				rawSub.Unsubscribe()
+				return nil, err
+			}
+		}
+	}
+	stream := event.NewSubscription(func(quit <-chan struct{}) error {
+		defer rawSub.Unsubscribe()
+		var headErr <-chan error
+		if headSub != nil {
+			defer headSub.Unsubscribe()
+			headErr = headSub.Err()
+		}
+		dedup := &logDeduper{seen: make(map[logKey]uint64)}
+		deliver := func(log types.Log) {
+			if dedup.admit(&log) {
+				handler.OnLog(&Log{&log})
+			}
+		}
+		last := head.Number.Uint64()
+		if bounded && upper < last {
+			last = upper
+		}
+		from := last
+		if query.query.FromBlock != nil && query.query.FromBlock.Sign() >= 0 {
+			from = lower
+		}
+		chunk := uint64(logStreamChunk)
+		for from <= last {
+			select {
+			case <-quit:
+				return nil
+			default:
+			}
+			to := from + chunk - 1
+			if to > last {
+				to = last
+			}
+			page := query.query
+			page.BlockHash = nil
+			page.FromBlock, page.ToBlock = new(big.Int).SetUint64(from), new(big.Int).SetUint64(to)
+			logs, err := ec.client.FilterLogs(ctx.context, page)
+			if err != nil {
+				if chunk > 1 {
+					chunk /= 2
+					continue
+				}
+				handler.OnError(err.Error())
+				return err
+			}
+			for _, log := range logs {
+				deliver(log)
+			}
+			from = to + 1
+			if chunk < logStreamChunk {
+				chunk *= 2
+			}
+		}
+		if bounded && headSub == nil {
+			handler.OnHistoryComplete(int64(last))
+			return nil
+		}
+		if !bounded {
+			handler.OnHistoryComplete(int64(last))
+		}
+		accept := func(log types.Log) bool {
+			if bounded && log.BlockNumber > upper {
+				return false
+			}
+			if log.BlockNumber >= lower && (log.Removed || log.BlockNumber+logStreamReorgWindow >= last) {
+				deliver(log)
+			}
+			return true
+		}
+		for {
+			select {
+			case log := <-ch:
+				if !accept(log) {
+					handler.OnHistoryComplete(int64(upper))
+					return nil
+				}
+			case header := <-heads:
+				if header.Number.Uint64() <= upper {
+					continue
+				}
+				for drained := false; !drained; {
+					select {
+					case log := <-ch:
+						accept(log)
+					default:
+						drained = true
+					}
+				}
+				handler.OnHistoryComplete(int64(upper))
+				return nil
+			case err := <-headErr:
+				if err != nil {
+					handler.OnError(err.Error())
+				}
+				return err
+			case err := <-rawSub.Err():
+				if err != nil {
+					handler.OnError(err.Error())
+				}
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	})
+	return &Subscription{stream}, nil
+}
+func (ec *EthereumClient) streamBlockLogs(ctx *Context, query *FilterQuery, handler LogStreamHandler) (*Subscription, error) {
+	header, err := ec.client.HeaderByHash(ctx.context, *query.query.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := ec.client.FilterLogs(ctx.context, query.query)
+	if err != nil {
+		return nil, err
+	}
+	stream := event.NewSubscription(func(quit <-chan struct{}) error {
+		for i := range logs {
+			select {
+			case <-quit:
+				return nil
+			default:
+			}
+			handler.OnLog(&Log{&logs[i]})
+		}
+		handler.OnHistoryComplete(header.Number.Int64())
+		return nil
+	})
+	return &Subscription{stream}, nil
+}
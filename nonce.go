@@ -0,0 +1,210 @@
+package geth
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"github.com/Cryptochain-VON/common"
+)
+type nonceState struct {
+	Account  common.Address `json:"account"`
+	Next     uint64         `json:"next"`
+	Reserved []uint64       `json:"reserved"`
+	Released []uint64       `json:"released"`
+	Sent     []uint64       `json:"sent"`
+}
+type NonceManager struct {
+	client   *EthereumClient
+	account  common.Address
+	path     string
+	next     uint64
+	synced   bool
+	reserved map[uint64]struct{}
+	released []uint64
+	sent     map[uint64]struct{}
+	lock     sync.Mutex
+}
+func NewNonceManager(client *EthereumClient, account *Address, path string) (manager *NonceManager, _ error) {
+	m := &NonceManager{
+		client:   client,
+		account:  account.address,
+		path:     path,
+		reserved: make(map[uint64]struct{}),
+		sent:     make(map[uint64]struct{}),
+	}
+	if path != "" {
+		blob, err := ioutil.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if len(blob) > 0 {
+			var state nonceState
+			if err := json.Unmarshal(blob, &state); err != nil {
+				return nil, err
+			}
+			if state.Account != m.account {
+				return nil, fmt.Errorf("nonce state belongs to %x, not %x", state.Account, m.account)
+			}
+			m.next = state.Next
+			for _, nonce := range state.Sent {
+				m.sent[nonce] = struct{}{}
+			}
+			for _, nonce := range append(state.Released, state.Reserved...) {
+				if _, ok := m.sent[nonce]; !ok {
+					m.released = append(m.released, nonce)
+				}
+			}
+			sort.Slice(m.released, func(i, j int) bool { return m.released[i] < m.released[j] })
+		}
+	}
+	return m, nil
+}
+func (m *NonceManager) GetAccount() *Address { return &Address{m.account} }
+func (m *NonceManager) GetNext() int64 {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return int64(m.next)
+}
+func (m *NonceManager) GetGaps() *BigInts {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	gaps := &BigInts{make([]*big.Int, len(m.released))}
+	for i, nonce := range m.released {
+		gaps.bigints[i] = new(big.Int).SetUint64(nonce)
+	}
+	return gaps
+}
+func (m *NonceManager) Reserve(ctx *Context) (nonce int64, _ error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if !m.synced {
+		if err := m.reconcile(ctx); err != nil {
+			return 0, err
+		}
+	}
+	var reserved uint64
+	if len(m.released) > 0 {
+		reserved, m.released = m.released[0], m.released[1:]
+	} else {
+		reserved = m.next
+		m.next++
+	}
+	m.reserved[reserved] = struct{}{}
+	if err := m.persist(); err != nil {
+		return 0, err
+	}
+	return int64(reserved), nil
+}
+func (m *NonceManager) Confirm(nonce int64) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.reserved, uint64(nonce))
+	if i := sort.Search(len(m.released), func(i int) bool { return m.released[i] >= uint64(nonce) }); i < len(m.released) && m.released[i] == uint64(nonce) {
+		m.released = append(m.released[:i], m.released[i+1:]...)
+	}
+	m.sent[uint64(nonce)] = struct{}{}
+	return m.persist()
+}
+func (m *NonceManager) Release(nonce int64) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.reserved[uint64(nonce)]; !ok {
+		return fmt.Errorf("nonce %d is not reserved", nonce)
+	}
+	delete(m.reserved, uint64(nonce))
+	m.release(uint64(nonce))
+	return m.persist()
+}
+func (m *NonceManager) release(nonce uint64) {
+	m.released = append(m.released, nonce)
+	sort.Slice(m.released, func(i, j int) bool { return m.released[i] < m.released[j] })
+	for len(m.released) > 0 && m.released[len(m.released)-1] == m.next-1 {
+		m.released = m.released[:len(m.released)-1]
+		m.next--
+	}
+}
+func (m *NonceManager) Reconcile(ctx *Context) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.reconcile(ctx)
+}
+func (m *NonceManager) reconcile(ctx *Context) error {
+	pending, err := m.client.client.PendingNonceAt(ctx.context, m.account)
+	if err != nil {
+		return err
+	}
+	latest, err := m.client.client.NonceAt(ctx.context, m.account, nil)
+	if err != nil {
+		return err
+	}
+	if pending < latest {
+		pending = latest
+	}
+	released := m.released[:0]
+	for _, nonce := range m.released {
+		if _, ok := m.sent[nonce]; !ok && nonce >= pending {
+			released = append(released, nonce)
+		}
+	}
+	m.released = released
+	for nonce := range m.reserved {
+		if nonce < latest {
+			delete(m.reserved, nonce)
+		}
+	}
+	for nonce := range m.sent {
+		if nonce < latest {
+			delete(m.sent, nonce)
+		}
+	}
+	if m.next < pending {
+		m.next = pending
+	}
+	for nonce := pending; nonce < m.next; nonce++ {
+		if _, ok := m.reserved[nonce]; ok {
+			continue
+		}
+		if _, ok := m.sent[nonce]; ok {
+			continue
+		}
+		if !m.isReleased(nonce) {
+			m.release(nonce)
+		}
+	}
+	m.synced = true
+	return m.persist()
+}
+func (m *NonceManager) isReleased(nonce uint64) bool {
+	i := sort.Search(len(m.released), func(i int) bool { return m.released[i] >= nonce })
+	return i < len(m.released) && m.released[i] == nonce
+}
+func (m *NonceManager) persist() error {
+	if m.path == "" {
+		return nil
+	}
+	state := nonceState{
+		Account:  m.account,
+		Next:     m.next,
+		Reserved: make([]uint64, 0, len(m.reserved)),
+		Released: m.released,
+		Sent:     make([]uint64, 0, len(m.sent)),
+	}
+	for nonce := range m.reserved {
+		state.Reserved = append(state.Reserved, nonce)
+	}
+	for nonce := range m.sent {
+		state.Sent = append(state.Sent, nonce)
+	}
+	blob, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(m.path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(m.path, blob, 0600)
+}
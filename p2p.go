@@ -1,6 +1,9 @@
 package geth
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math/big"
 	"github.com/Cryptochain-VON/p2p"
 )
 type NodeInfo struct {
@@ -13,6 +16,7 @@ func (ni *NodeInfo) GetIP() string              { return ni.info.IP }
 func (ni *NodeInfo) GetDiscoveryPort() int      { return ni.info.Ports.Discovery }
 func (ni *NodeInfo) GetListenerPort() int       { return ni.info.Ports.Listener }
 func (ni *NodeInfo) GetListenerAddress() string { return ni.info.ListenAddr }
+func (ni *NodeInfo) GetENR() string             { return ni.info.ENR }
 func (ni *NodeInfo) GetProtocols() *Strings {
 	protos := []string{}
 	for proto := range ni.info.Protocols {
@@ -20,16 +24,88 @@ func (ni *NodeInfo) GetProtocols() *Strings {
 	}
 	return &Strings{protos}
 }
+func (ni *NodeInfo) GetProtocolInfo(proto string) (string, error) {
+	return encodeProtocolInfo(ni.info.Protocols, proto)
+}
+func (ni *NodeInfo) EncodeJSON() (string, error) {
+	data, err := json.Marshal(ni.info)
+	return string(data), err
+}
+type protocolInfo struct {
+	Version    int      `json:"version"`
+	Difficulty *big.Int `json:"difficulty"`
+	Head       string   `json:"head"`
+}
+func encodeProtocolInfo(protos map[string]interface{}, proto string) (string, error) {
+	info, ok := protos[proto]
+	if !ok {
+		return "", fmt.Errorf("unknown protocol: %s", proto)
+	}
+	data, err := json.Marshal(info)
+	return string(data), err
+}
+func decodeProtocolInfo(protos map[string]interface{}, proto string) *protocolInfo {
+	info := new(protocolInfo)
+	if data, err := encodeProtocolInfo(protos, proto); err == nil {
+		json.Unmarshal([]byte(data), info)
+	}
+	return info
+}
+type peerTraffic struct {
+	Ingress uint64 `json:"ingress"`
+	Egress  uint64 `json:"egress"`
+	Latency uint64 `json:"latencyMs"`
+}
 type PeerInfo struct {
-	info *p2p.PeerInfo
+	info    *p2p.PeerInfo
+	traffic peerTraffic
 }
 func (pi *PeerInfo) GetID() string            { return pi.info.ID }
 func (pi *PeerInfo) GetName() string          { return pi.info.Name }
+func (pi *PeerInfo) GetEnode() string         { return pi.info.Enode }
+func (pi *PeerInfo) GetENR() string           { return pi.info.ENR }
 func (pi *PeerInfo) GetCaps() *Strings        { return &Strings{pi.info.Caps} }
 func (pi *PeerInfo) GetLocalAddress() string  { return pi.info.Network.LocalAddress }
 func (pi *PeerInfo) GetRemoteAddress() string { return pi.info.Network.RemoteAddress }
+func (pi *PeerInfo) IsInbound() bool          { return pi.info.Network.Inbound }
+func (pi *PeerInfo) IsTrusted() bool          { return pi.info.Network.Trusted }
+func (pi *PeerInfo) IsStatic() bool           { return pi.info.Network.Static }
+func (pi *PeerInfo) GetIngressBytes() int64   { return int64(pi.traffic.Ingress) }
+func (pi *PeerInfo) GetEgressBytes() int64    { return int64(pi.traffic.Egress) }
+func (pi *PeerInfo) GetLatency() int64        { return int64(pi.traffic.Latency) }
+func (pi *PeerInfo) GetProtocols() *Strings {
+	protos := []string{}
+	for proto := range pi.info.Protocols {
+		protos = append(protos, proto)
+	}
+	return &Strings{protos}
+}
+func (pi *PeerInfo) GetProtocolInfo(proto string) (string, error) {
+	return encodeProtocolInfo(pi.info.Protocols, proto)
+}
+func (pi *PeerInfo) GetProtocolVersion(proto string) int {
+	return decodeProtocolInfo(pi.info.Protocols, proto).Version
+}
+func (pi *PeerInfo) GetProtocolHead(proto string) string {
+	return decodeProtocolInfo(pi.info.Protocols, proto).Head
+}
+func (pi *PeerInfo) GetProtocolDifficulty(proto string) *BigInt {
+	if td := decodeProtocolInfo(pi.info.Protocols, proto).Difficulty; td != nil {
+		return &BigInt{td}
+	}
+	return nil
+}
+func (pi *PeerInfo) EncodeJSON() (string, error) {
+	data, err := json.Marshal(&peerInfoJSON{pi.info, pi.traffic})
+	return string(data), err
+}
+type peerInfoJSON struct {
+	*p2p.PeerInfo
+	Traffic peerTraffic `json:"traffic"`
+}
 type PeerInfos struct {
-	infos []*p2p.PeerInfo
+	infos   []*p2p.PeerInfo
+	traffic map[string]peerTraffic
 }
 func (pi *PeerInfos) Size() int {
 	return len(pi.infos)
@@ -38,5 +114,58 @@ func (pi *PeerInfos) Get(index int) (info *PeerInfo, _ error) {
 	if index < 0 || index >= len(pi.infos) {
 		return nil, errors.New("index out of bounds")
 	}
-	return &PeerInfo{pi.infos[index]}, nil
+	return &PeerInfo{info: pi.infos[index], traffic: pi.traffic[pi.infos[index].ID]}, nil
+}
+func (pi *PeerInfos) EncodeJSON() (string, error) {
+	peers := make([]*peerInfoJSON, len(pi.infos))
+	for i, info := range pi.infos {
+		peers[i] = &peerInfoJSON{info, pi.traffic[info.ID]}
+	}
+	data, err := json.Marshal(peers)
+	return string(data), err
+}
+const (
+	PeerEventAdd      = "add"
+	PeerEventDrop     = "drop"
+	PeerEventMsgError = "msgerror"
+)
+type PeerEvent struct {
+	kind   string
+	info   *p2p.PeerInfo
+	reason string
+}
+func (pe *PeerEvent) GetType() string        { return pe.kind }
+func (pe *PeerEvent) GetPeerID() string      { return pe.info.ID }
+func (pe *PeerEvent) GetPeerInfo() *PeerInfo { return &PeerInfo{info: pe.info} }
+func (pe *PeerEvent) GetReason() string      { return pe.reason }
+type PeerEventHandler interface {
+	OnPeerEvent(event *PeerEvent)
+	OnError(failure string)
+}
+type LightServerStats struct {
+	serv    int
+	peers   int
+	ingress int
+	egress  int
+	clients int
+	info    lightServerInfo
+}
+type lightServerInfo struct {
+	Total     uint64 `json:"totalCapacity"`
+	Connected uint64 `json:"totalConnectedCapacity"`
+	Priority  uint64 `json:"priorityConnectedCapacity"`
+	Free      uint64 `json:"freeClientCapacity"`
+	Minimum   uint64 `json:"minimumCapacity"`
+	Maximum   uint64 `json:"maximumCapacity"`
 }
+func (s *LightServerStats) GetServingPercentage() int           { return s.serv }
+func (s *LightServerStats) GetMaxClients() int                  { return s.peers }
+func (s *LightServerStats) GetIngressLimit() int                { return s.ingress }
+func (s *LightServerStats) GetEgressLimit() int                 { return s.egress }
+func (s *LightServerStats) GetConnectedClients() int            { return s.clients }
+func (s *LightServerStats) GetTotalCapacity() int64             { return int64(s.info.Total) }
+func (s *LightServerStats) GetConnectedCapacity() int64         { return int64(s.info.Connected) }
+func (s *LightServerStats) GetPriorityConnectedCapacity() int64 { return int64(s.info.Priority) }
+func (s *LightServerStats) GetFreeClientCapacity() int64        { return int64(s.info.Free) }
+func (s *LightServerStats) GetMinimumCapacity() int64           { return int64(s.info.Minimum) }
+func (s *LightServerStats) GetMaximumCapacity() int64           { return int64(s.info.Maximum) }
@@ -1,6 +1,11 @@
 package geth
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"github.com/Cryptochain-VON/common"
 	"github.com/Cryptochain-VON/core"
 	"github.com/Cryptochain-VON/p2p/discv5"
 	"github.com/Cryptochain-VON/params"
@@ -36,3 +41,181 @@ func FoundationBootnodes() *Enodes {
 	}
 	return nodes
 }
+type NetworkPreset struct {
+	name       string
+	genesis    *core.Genesis
+	hash       common.Hash
+	config     *params.ChainConfig
+	networkID  int64
+	bootnodes  *Enodes
+	checkpoint *params.TrustedCheckpoint
+}
+type Checkpoint struct {
+	checkpoint params.TrustedCheckpoint
+}
+func NewCheckpoint(sectionIndex int64, sectionHead *Hash, chtRoot *Hash, bloomRoot *Hash) *Checkpoint {
+	return &Checkpoint{params.TrustedCheckpoint{
+		SectionIndex: uint64(sectionIndex),
+		SectionHead:  sectionHead.hash,
+		CHTRoot:      chtRoot.hash,
+		BloomRoot:    bloomRoot.hash,
+	}}
+}
+func (c *Checkpoint) GetSectionIndex() int64 { return int64(c.checkpoint.SectionIndex) }
+func (c *Checkpoint) GetSectionHead() *Hash  { return &Hash{c.checkpoint.SectionHead} }
+func (c *Checkpoint) GetCHTRoot() *Hash      { return &Hash{c.checkpoint.CHTRoot} }
+func (c *Checkpoint) GetBloomRoot() *Hash    { return &Hash{c.checkpoint.BloomRoot} }
+func (c *Checkpoint) GetHash() *Hash         { return &Hash{c.checkpoint.Hash()} }
+type CheckpointOracle struct {
+	oracle params.CheckpointOracleConfig
+}
+func NewCheckpointOracle(address *Address, signers *Addresses, threshold int) (oracle *CheckpointOracle, _ error) {
+	if signers == nil || signers.Size() == 0 {
+		return nil, errors.New("no checkpoint signers")
+	}
+	if threshold <= 0 || threshold > signers.Size() {
+		return nil, fmt.Errorf("invalid signer threshold %d for %d signers", threshold, signers.Size())
+	}
+	return &CheckpointOracle{params.CheckpointOracleConfig{
+		Address:   address.address,
+		Signers:   append([]common.Address{}, signers.addresses...),
+		Threshold: uint64(threshold),
+	}}, nil
+}
+func (o *CheckpointOracle) GetAddress() *Address   { return &Address{o.oracle.Address} }
+func (o *CheckpointOracle) GetSigners() *Addresses { return &Addresses{o.oracle.Signers} }
+func (o *CheckpointOracle) GetThreshold() int      { return int(o.oracle.Threshold) }
+type CheckpointStatus struct {
+	checkpoint *Checkpoint
+	oracle     *Address
+}
+func (s *CheckpointStatus) GetCheckpoint() *Checkpoint { return s.checkpoint }
+func (s *CheckpointStatus) GetOracleAddress() *Address { return s.oracle }
+func (s *CheckpointStatus) HasOracle() bool            { return s.oracle != nil }
+func NewNetworkPreset(name string, genesis string, networkID int64) (preset *NetworkPreset, _ error) {
+	if name == "" {
+		return nil, errors.New("empty network name")
+	}
+	spec := new(core.Genesis)
+	if err := json.Unmarshal([]byte(genesis), spec); err != nil {
+		return nil, fmt.Errorf("invalid genesis spec: %v", err)
+	}
+	return &NetworkPreset{
+		name:      name,
+		genesis:   spec,
+		hash:      spec.ToBlock(nil).Hash(),
+		config:    spec.Config,
+		networkID: networkID,
+	}, nil
+}
+func (p *NetworkPreset) GetName() string       { return p.name }
+func (p *NetworkPreset) GetNetworkID() int64   { return p.networkID }
+func (p *NetworkPreset) GetGenesisHash() *Hash { return &Hash{p.hash} }
+func (p *NetworkPreset) GetBootnodes() *Enodes { return p.bootnodes }
+func (p *NetworkPreset) GetGenesis() string {
+	if p.genesis == nil {
+		return ""
+	}
+	enc, err := json.Marshal(p.genesis)
+	if err != nil {
+		panic(err)
+	}
+	return string(enc)
+}
+func (p *NetworkPreset) GetChainConfig() string {
+	enc, err := json.Marshal(p.config)
+	if err != nil {
+		panic(err)
+	}
+	return string(enc)
+}
+func (p *NetworkPreset) SetChainConfig(config string) error {
+	chainConfig := new(params.ChainConfig)
+	if err := json.Unmarshal([]byte(config), chainConfig); err != nil {
+		return fmt.Errorf("invalid chain config: %v", err)
+	}
+	p.config = chainConfig
+	return nil
+}
+func (p *NetworkPreset) SetBootnodes(bootnodes *Enodes) { p.bootnodes = bootnodes }
+func (p *NetworkPreset) GetCheckpoint() *Checkpoint {
+	if p.checkpoint == nil {
+		return nil
+	}
+	return &Checkpoint{*p.checkpoint}
+}
+func (p *NetworkPreset) SetCheckpoint(checkpoint *Checkpoint) {
+	if checkpoint == nil {
+		p.checkpoint = nil
+		return
+	}
+	cp := checkpoint.checkpoint
+	p.checkpoint = &cp
+}
+var (
+	networkPresets     map[string]*NetworkPreset
+	networkPresetsLock sync.RWMutex
+	networkPresetsOnce sync.Once
+)
+func builtinNetworkPreset(name string, genesis *core.Genesis, hash common.Hash, config *params.ChainConfig, networkID int64, bootnodes []string, checkpoint *params.TrustedCheckpoint) *NetworkPreset {
+	nodes := &Enodes{nodes: make([]*discv5.Node, len(bootnodes))}
+	for i, url := range bootnodes {
+		nodes.nodes[i] = discv5.MustParseNode(url)
+	}
+	return &NetworkPreset{
+		name:       name,
+		genesis:    genesis,
+		hash:       hash,
+		config:     config,
+		networkID:  networkID,
+		bootnodes:  nodes,
+		checkpoint: checkpoint,
+	}
+}
+func loadNetworkPresets() {
+	networkPresetsOnce.Do(func() {
+		networkPresets = make(map[string]*NetworkPreset)
+		for _, preset := range []*NetworkPreset{
+			builtinNetworkPreset("mainnet", nil, params.MainnetGenesisHash, params.MainnetChainConfig, 1, params.MainnetBootnodes, params.MainnetTrustedCheckpoint),
+			builtinNetworkPreset("ropsten", core.DefaultRopstenGenesisBlock(), params.RopstenGenesisHash, params.RopstenChainConfig, 3, params.RopstenBootnodes, params.RopstenTrustedCheckpoint),
+			builtinNetworkPreset("rinkeby", core.DefaultRinkebyGenesisBlock(), params.RinkebyGenesisHash, params.RinkebyChainConfig, 4, params.RinkebyBootnodes, params.RinkebyTrustedCheckpoint),
+			builtinNetworkPreset("goerli", core.DefaultGoerliGenesisBlock(), params.GoerliGenesisHash, params.GoerliChainConfig, 5, params.GoerliBootnodes, params.GoerliTrustedCheckpoint),
+		} {
+			networkPresets[preset.name] = preset
+		}
+	})
+}
+func RegisterNetworkPreset(preset *NetworkPreset) error {
+	if preset == nil {
+		return errors.New("nil network preset")
+	}
+	loadNetworkPresets()
+	networkPresetsLock.Lock()
+	defer networkPresetsLock.Unlock()
+	name := strings.ToLower(preset.name)
+	if _, ok := networkPresets[name]; ok {
+		return fmt.Errorf("network preset %q already registered", preset.name)
+	}
+	networkPresets[name] = preset
+	return nil
+}
+func GetNetworkPreset(name string) (preset *NetworkPreset, _ error) {
+	loadNetworkPresets()
+	networkPresetsLock.RLock()
+	defer networkPresetsLock.RUnlock()
+	if preset, ok := networkPresets[strings.ToLower(name)]; ok {
+		return preset, nil
+	}
+	return nil, fmt.Errorf("unknown network preset %q", name)
+}
+func findNetworkPreset(hash common.Hash) *NetworkPreset {
+	loadNetworkPresets()
+	networkPresetsLock.RLock()
+	defer networkPresetsLock.RUnlock()
+	for _, preset := range networkPresets {
+		if preset.hash == hash {
+			return preset
+		}
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+package geth
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"github.com/Cryptochain-VON/common"
+	"github.com/Cryptochain-VON/event"
+	"github.com/Cryptochain-VON/p2p"
+	"github.com/Cryptochain-VON/p2p/enode"
+)
+const (
+	staticNodesFile    = "static-nodes.json"
+	trustedNodesFile   = "trusted-nodes.json"
+	peerRequestTimeout = 10 * time.Second
+)
+func loadPersistentNodes(configured *Enodes, dir string, file string) ([]*enode.Node, error) {
+	var nodes []*enode.Node
+	if configured != nil {
+		parsed, err := configured.v4()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, parsed...)
+	}
+	if dir == "" {
+		return nodes, nil
+	}
+	var urls []string
+	if err := common.LoadJSON(filepath.Join(dir, file), &urls); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	seen := make(map[enode.ID]bool)
+	for _, node := range nodes {
+		seen[node.ID()] = true
+	}
+	for _, url := range urls {
+		node, err := enode.ParseV4(url)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[node.ID()] {
+			seen[node.ID()] = true
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+func (n *Node) persistNode(file string, node *enode.Node, add bool) error {
+	if n.nodesDir == "" {
+		return nil
+	}
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	path := filepath.Join(n.nodesDir, file)
+	var urls []string
+	if err := common.LoadJSON(path, &urls); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	kept := make([]string, 0, len(urls)+1)
+	for _, url := range urls {
+		if old, err := enode.ParseV4(url); err == nil && old.ID() == node.ID() {
+			continue
+		}
+		kept = append(kept, url)
+	}
+	if add {
+		kept = append(kept, node.URLv4())
+	}
+	blob, err := json.MarshalIndent(kept, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(n.nodesDir, 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, blob, 0600)
+}
+func (n *Node) peerServer(peer *Enode) (*p2p.Server, *enode.Node, error) {
+	if peer == nil {
+		return nil, nil, errors.New("nil peer")
+	}
+	node, err := peer.v4()
+	if err != nil {
+		return nil, nil, err
+	}
+	server := n.node.Server()
+	if server == nil {
+		return nil, nil, errors.New("node not started")
+	}
+	return server, node, nil
+}
+func (n *Node) AddPeer(peer *Enode) error {
+	server, node, err := n.peerServer(peer)
+	if err != nil {
+		return err
+	}
+	n.dialer.pin(n.dialer.static, node.ID(), true)
+	server.AddPeer(node)
+	return n.persistNode(staticNodesFile, node, true)
+}
+func (n *Node) RemovePeer(peer *Enode) error {
+	server, node, err := n.peerServer(peer)
+	if err != nil {
+		return err
+	}
+	server.RemovePeer(node)
+	n.dialer.pin(n.dialer.static, node.ID(), false)
+	return n.persistNode(staticNodesFile, node, false)
+}
+func (n *Node) AddTrustedPeer(peer *Enode) error {
+	server, node, err := n.peerServer(peer)
+	if err != nil {
+		return err
+	}
+	n.dialer.pin(n.dialer.trusted, node.ID(), true)
+	server.AddTrustedPeer(node)
+	return n.persistNode(trustedNodesFile, node, true)
+}
+func (n *Node) RemoveTrustedPeer(peer *Enode) error {
+	server, node, err := n.peerServer(peer)
+	if err != nil {
+		return err
+	}
+	server.RemoveTrustedPeer(node)
+	n.dialer.pin(n.dialer.trusted, node.ID(), false)
+	return n.persistNode(trustedNodesFile, node, false)
+}
+func (n *Node) SubscribePeerEvents(handler PeerEventHandler) (sub *Subscription, _ error) {
+	ch := make(chan *PeerEvent, 64)
+	rawSub := n.peerScope.Track(n.peerFeed.Subscribe(ch))
+	if rawSub == nil {
+		return nil, errNodeClosed
+	}
+	out := make(chan *PeerEvent)
+	go func() {
+		for ev := range out {
+			handler.OnPeerEvent(ev)
+		}
+	}()
+	go func() {
+		defer close(out)
+		var queue []*PeerEvent
+		for {
+			var (
+				next *PeerEvent
+				send chan *PeerEvent
+			)
+			if len(queue) > 0 {
+				next, send = queue[0], out
+			}
+			select {
+			case ev := <-ch:
+				queue = append(queue, ev)
+			case send <- next:
+				queue = queue[1:]
+			case err := <-rawSub.Err():
+				if err != nil {
+					handler.OnError(err.Error())
+				}
+				return
+			}
+		}
+	}()
+	return &Subscription{rawSub}, nil
+}
+func (n *Node) relayPeerEvents(server *p2p.Server) event.Subscription {
+	ch := make(chan *p2p.PeerEvent, 64)
+	sub := server.SubscribeEvents(ch)
+	known := make(map[string]*p2p.PeerInfo)
+	for _, info := range server.PeersInfo() {
+		known[info.ID] = info
+	}
+	go func() {
+		for {
+			select {
+			case ev := <-ch:
+				id := ev.Peer.String()
+				switch ev.Type {
+				case p2p.PeerEventTypeAdd:
+					info := &p2p.PeerInfo{ID: id}
+					for _, peer := range server.PeersInfo() {
+						if peer.ID == id {
+							info = peer
+							break
+						}
+					}
+					known[id] = info
+					n.peerFeed.Send(&PeerEvent{kind: PeerEventAdd, info: info})
+				case p2p.PeerEventTypeDrop:
+					info, ok := known[id]
+					if !ok {
+						info = &p2p.PeerInfo{ID: id}
+						info.Network.LocalAddress = ev.LocalAddress
+						info.Network.RemoteAddress = ev.RemoteAddress
+					}
+					delete(known, id)
+					n.peerFeed.Send(&PeerEvent{kind: dropKind(ev.Error), info: info, reason: ev.Error})
+				}
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub
+}
+var discReasons = func() map[string]p2p.DiscReason {
+	reasons := make(map[string]p2p.DiscReason)
+	for r := p2p.DiscRequested; r <= p2p.DiscSubprotocolError; r++ {
+		reasons[r.Error()] = r
+	}
+	return reasons
+}()
+func dropKind(reason string) string {
+	if r, ok := discReasons[reason]; ok {
+		if r == p2p.DiscProtocolError || r == p2p.DiscSubprotocolError {
+			return PeerEventMsgError
+		}
+		return PeerEventDrop
+	}
+	if reason == "" || isNetworkError(reason) {
+		return PeerEventDrop
+	}
+	return PeerEventMsgError
+}
+func isNetworkError(reason string) bool {
+	for _, marker := range []string{"EOF", "read ", "write ", "i/o timeout", "closed network connection", "connection reset", "broken pipe"} {
+		if strings.Contains(reason, marker) {
+			return true
+		}
+	}
+	return false
+}
+var replyCodes = map[string]map[uint64]uint64{
+	"eth": {0x03: 0x04, 0x05: 0x06, 0x0d: 0x0e, 0x0f: 0x10},
+	"les": {0x02: 0x03, 0x04: 0x05, 0x06: 0x07, 0x0a: 0x0b, 0x0f: 0x10, 0x11: 0x12, 0x13: 0x15, 0x14: 0x15},
+}
+type replyKey struct {
+	protocol string
+	code     uint64
+}
+type peerState struct {
+	traffic peerTraffic
+	rtt     time.Duration
+	pending map[replyKey][]time.Time
+}
+type trafficTracker struct {
+	peers map[string]*peerState
+	sub   event.Subscription
+	lock  sync.RWMutex
+}
+func newTrafficTracker(server *p2p.Server) *trafficTracker {
+	ch := make(chan *p2p.PeerEvent, 256)
+	t := &trafficTracker{
+		peers: make(map[string]*peerState),
+		sub:   server.SubscribeEvents(ch),
+	}
+	go t.loop(ch)
+	return t
+}
+func (t *trafficTracker) loop(ch chan *p2p.PeerEvent) {
+	for {
+		select {
+		case ev := <-ch:
+			id := ev.Peer.String()
+			t.lock.Lock()
+			switch ev.Type {
+			case p2p.PeerEventTypeDrop:
+				delete(t.peers, id)
+			case p2p.PeerEventTypeMsgRecv, p2p.PeerEventTypeMsgSend:
+				state, ok := t.peers[id]
+				if !ok {
+					state = &peerState{pending: make(map[replyKey][]time.Time)}
+					t.peers[id] = state
+				}
+				if ev.MsgSize != nil {
+					if ev.Type == p2p.PeerEventTypeMsgRecv {
+						state.traffic.Ingress += uint64(*ev.MsgSize)
+					} else {
+						state.traffic.Egress += uint64(*ev.MsgSize)
+					}
+				}
+				if ev.MsgCode != nil {
+					state.measure(ev.Type, ev.Protocol, *ev.MsgCode)
+				}
+			}
+			t.lock.Unlock()
+		case <-t.sub.Err():
+			return
+		}
+	}
+}
+func (s *peerState) measure(kind p2p.PeerEventType, protocol string, code uint64) {
+	now := time.Now()
+	if kind == p2p.PeerEventTypeMsgSend {
+		if reply, ok := replyCodes[protocol][code]; ok {
+			key := replyKey{protocol, reply}
+			pending := expireRequests(s.pending[key], now)
+			if len(pending) < 16 {
+				s.pending[key] = append(pending, now)
+			}
+		}
+		return
+	}
+	key := replyKey{protocol, code}
+	pending := expireRequests(s.pending[key], now)
+	if len(pending) == 0 {
+		delete(s.pending, key)
+		return
+	}
+	sample := now.Sub(pending[0])
+	s.pending[key] = pending[1:]
+	if s.rtt == 0 {
+		s.rtt = sample
+	} else {
+		s.rtt += (sample - s.rtt) / 5
+	}
+	s.traffic.Latency = uint64(s.rtt / time.Millisecond)
+}
+func expireRequests(pending []time.Time, now time.Time) []time.Time {
+	for len(pending) > 0 && now.Sub(pending[0]) > peerRequestTimeout {
+		pending = pending[1:]
+	}
+	return pending
+}
+func (t *trafficTracker) snapshot() map[string]peerTraffic {
+	if t == nil {
+		return nil
+	}
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	snapshot := make(map[string]peerTraffic, len(t.peers))
+	for id, state := range t.peers {
+		snapshot[id] = state.traffic
+	}
+	return snapshot
+}
+func (t *trafficTracker) stop() {
+	t.sub.Unsubscribe()
+}
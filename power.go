@@ -0,0 +1,184 @@
+package geth
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+	"github.com/Cryptochain-VON/eth"
+	"github.com/Cryptochain-VON/eth/downloader"
+	"github.com/Cryptochain-VON/event"
+	"github.com/Cryptochain-VON/les"
+	"github.com/Cryptochain-VON/p2p"
+	"github.com/Cryptochain-VON/p2p/enode"
+)
+type PowerProfile struct {
+	MaxPeers  int
+	PauseSync bool
+}
+var defaultBackgroundProfile = PowerProfile{
+	MaxPeers:  1,
+	PauseSync: true,
+}
+func NewPowerProfile() *PowerProfile {
+	profile := defaultBackgroundProfile
+	return &profile
+}
+type backgroundState struct {
+	profile    PowerProfile
+	server     *p2p.Server
+	downloader *downloader.Downloader
+	peerSub    event.Subscription
+	syncSub    *event.TypeMuxSubscription
+	quit       chan struct{}
+	done       chan struct{}
+}
+func (n *Node) IsInBackground() bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.bg != nil
+}
+func (n *Node) EnterBackground() error {
+	n.lifecycle.Lock()
+	defer n.lifecycle.Unlock()
+	if n.GetStatus() != NodeStatusRunning {
+		return errors.New("node not running")
+	}
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if n.bg != nil {
+		return nil
+	}
+	bg := &backgroundState{
+		profile: *n.config.BackgroundProfile,
+		server:  n.node.Server(),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	peerCh := make(chan *p2p.PeerEvent, 64)
+	bg.peerSub = bg.server.SubscribeEvents(peerCh)
+	var syncCh <-chan *event.TypeMuxEvent
+	if bg.profile.PauseSync {
+		if bg.downloader = n.syncDownloader(); bg.downloader != nil {
+			bg.syncSub = n.node.EventMux().Subscribe(downloader.StartEvent{})
+			syncCh = bg.syncSub.Chan()
+			bg.downloader.Cancel()
+		}
+	}
+	n.dialer.pause(true)
+	bg.trimPeers(bg.profile.MaxPeers)
+	go bg.loop(peerCh, syncCh)
+	n.bg = bg
+	return nil
+}
+func (n *Node) EnterForeground() {
+	n.lifecycle.Lock()
+	defer n.lifecycle.Unlock()
+	n.enterForeground()
+}
+func (n *Node) enterForeground() {
+	n.lock.Lock()
+	bg := n.bg
+	n.bg = nil
+	n.lock.Unlock()
+	if bg != nil {
+		close(bg.quit)
+		<-bg.done
+		n.dialer.pause(false)
+	}
+}
+func (n *Node) syncDownloader() *downloader.Downloader {
+	var lesServ *les.LightEthereum
+	if err := n.node.Service(&lesServ); err == nil {
+		return lesServ.Downloader()
+	}
+	var ethServ *eth.Ethereum
+	if err := n.node.Service(&ethServ); err == nil {
+		return ethServ.Downloader()
+	}
+	return nil
+}
+func (bg *backgroundState) loop(peerCh chan *p2p.PeerEvent, syncCh <-chan *event.TypeMuxEvent) {
+	defer close(bg.done)
+	defer bg.peerSub.Unsubscribe()
+	if bg.syncSub != nil {
+		defer bg.syncSub.Unsubscribe()
+	}
+	for {
+		select {
+		case ev := <-peerCh:
+			if ev.Type == p2p.PeerEventTypeAdd {
+				bg.trimPeers(bg.profile.MaxPeers)
+			}
+		case <-syncCh:
+			bg.downloader.Cancel()
+		case <-bg.peerSub.Err():
+			return
+		case <-bg.quit:
+			return
+		}
+	}
+}
+func (bg *backgroundState) trimPeers(limit int) {
+	peers := bg.server.PeersInfo()
+	for i := len(peers) - 1; i >= 0 && len(peers) > limit; i-- {
+		if peers[i].Network.Trusted || peers[i].Network.Static {
+			continue
+		}
+		if bg.disconnect(peers[i]) {
+			peers = append(peers[:i], peers[i+1:]...)
+		}
+	}
+}
+func (bg *backgroundState) disconnect(info *p2p.PeerInfo) bool {
+	node, err := enode.ParseV4(info.Enode)
+	if err != nil {
+		return false
+	}
+	bg.server.RemovePeer(node)
+	return true
+}
+var errDialPaused = errors.New("dialing paused in background")
+type gatedDialer struct {
+	dialer  p2p.NodeDialer
+	paused  bool
+	static  map[enode.ID]bool
+	trusted map[enode.ID]bool
+	lock    sync.RWMutex
+}
+func newGatedDialer(static, trusted []*enode.Node) *gatedDialer {
+	d := &gatedDialer{
+		dialer:  p2p.TCPDialer{Dialer: &net.Dialer{Timeout: 15 * time.Second}},
+		static:  make(map[enode.ID]bool),
+		trusted: make(map[enode.ID]bool),
+	}
+	for _, node := range static {
+		d.static[node.ID()] = true
+	}
+	for _, node := range trusted {
+		d.trusted[node.ID()] = true
+	}
+	return d
+}
+func (d *gatedDialer) Dial(dest *enode.Node) (net.Conn, error) {
+	d.lock.RLock()
+	blocked := d.paused && !d.static[dest.ID()] && !d.trusted[dest.ID()]
+	d.lock.RUnlock()
+	if blocked {
+		return nil, errDialPaused
+	}
+	return d.dialer.Dial(dest)
+}
+func (d *gatedDialer) pause(paused bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.paused = paused
+}
+func (d *gatedDialer) pin(set map[enode.ID]bool, id enode.ID, pinned bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if pinned {
+		set[id] = true
+	} else {
+		delete(set, id)
+	}
+}
@@ -5,6 +5,14 @@ import (
 	"github.com/Cryptochain-VON/common"
 )
 type Strings struct{ strs []string }
+func NewStrings(size int) *Strings {
+	return &Strings{
+		strs: make([]string, size),
+	}
+}
+func NewStringsEmpty() *Strings {
+	return NewStrings(0)
+}
 func (s *Strings) Size() int {
 	return len(s.strs)
 }
@@ -21,6 +29,9 @@ func (s *Strings) Set(index int, str string) error {
 	s.strs[index] = str
 	return nil
 }
+func (s *Strings) Append(str string) {
+	s.strs = append(s.strs, str)
+}
 func (s *Strings) String() string {
 	return fmt.Sprintf("%v", s.strs)
 }
@@ -44,6 +55,37 @@ func (bs *Bools) Set(index int, b bool) error {
 func (bs *Bools) String() string {
 	return fmt.Sprintf("%v", bs.bools)
 }
+type Floats struct{ floats []float64 }
+func NewFloats(size int) *Floats {
+	return &Floats{
+		floats: make([]float64, size),
+	}
+}
+func NewFloatsEmpty() *Floats {
+	return NewFloats(0)
+}
+func (fs *Floats) Size() int {
+	return len(fs.floats)
+}
+func (fs *Floats) Get(index int) (f float64, _ error) {
+	if index < 0 || index >= len(fs.floats) {
+		return 0, errors.New("index out of bounds")
+	}
+	return fs.floats[index], nil
+}
+func (fs *Floats) Set(index int, f float64) error {
+	if index < 0 || index >= len(fs.floats) {
+		return errors.New("index out of bounds")
+	}
+	fs.floats[index] = f
+	return nil
+}
+func (fs *Floats) Append(f float64) {
+	fs.floats = append(fs.floats, f)
+}
+func (fs *Floats) String() string {
+	return fmt.Sprintf("%v", fs.floats)
+}
 type Binaries struct{ binaries [][]byte }
 func (bs *Binaries) Size() int {
 	return len(bs.binaries)
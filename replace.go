@@ -0,0 +1,115 @@
+package geth
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"github.com/Cryptochain-VON/common"
+	"github.com/Cryptochain-VON/core"
+	"github.com/Cryptochain-VON/core/types"
+	"github.com/Cryptochain-VON/params"
+)
+type Replacement struct {
+	original    common.Hash
+	replacement *types.Transaction
+	cancel      bool
+}
+func (r *Replacement) GetOriginalHash() *Hash       { return &Hash{r.original} }
+func (r *Replacement) GetTransaction() *Transaction { return &Transaction{r.replacement} }
+func (r *Replacement) GetHash() *Hash               { return &Hash{r.replacement.Hash()} }
+func (r *Replacement) IsCancel() bool               { return r.cancel }
+type TxReplacer struct {
+	client   *EthereumClient
+	keystore *KeyStore
+	account  *Account
+	signer   *TxSigner
+	tracker  *TxTracker
+}
+func NewTxReplacer(client *EthereumClient, ks *KeyStore, account *Account, signer *TxSigner) *TxReplacer {
+	return &TxReplacer{
+		client:   client,
+		keystore: ks,
+		account:  account,
+		signer:   signer,
+	}
+}
+func (r *TxReplacer) SetTracker(tracker *TxTracker) {
+	r.tracker = tracker
+}
+func GetMinReplacementGasPrice(tx *Transaction) *BigInt {
+	return &BigInt{minReplacementGasPrice(tx.tx.GasPrice())}
+}
+func minReplacementGasPrice(price *big.Int) *big.Int {
+	bump := new(big.Int).Mul(price, big.NewInt(int64(100+core.DefaultTxPoolConfig.PriceBump)))
+	bump.Add(bump, big.NewInt(99))
+	bump.Div(bump, big.NewInt(100))
+	if bump.Cmp(price) <= 0 {
+		bump.Add(price, common.Big1)
+	}
+	return bump
+}
+func (r *TxReplacer) ReplaceTransaction(ctx *Context, tx *Transaction, newFee *BigInt) (replacement *Replacement, _ error) {
+	if err := r.checkReplaceable(ctx, tx); err != nil {
+		return nil, err
+	}
+	price := minReplacementGasPrice(tx.tx.GasPrice())
+	if newFee != nil {
+		if newFee.bigint.Cmp(price) < 0 {
+			return nil, fmt.Errorf("gas price %v below replacement minimum %v", newFee.bigint, price)
+		}
+		price = newFee.bigint
+	}
+	var rawTx *types.Transaction
+	if to := tx.tx.To(); to != nil {
+		rawTx = types.NewTransaction(tx.tx.Nonce(), *to, tx.tx.Value(), tx.tx.Gas(), price, tx.tx.Data())
+	} else {
+		rawTx = types.NewContractCreation(tx.tx.Nonce(), tx.tx.Value(), tx.tx.Gas(), price, tx.tx.Data())
+	}
+	return r.send(ctx, tx, rawTx, false)
+}
+func (r *TxReplacer) CancelTransaction(ctx *Context, tx *Transaction) (replacement *Replacement, _ error) {
+	if err := r.checkReplaceable(ctx, tx); err != nil {
+		return nil, err
+	}
+	price := minReplacementGasPrice(tx.tx.GasPrice())
+	if suggested, err := r.client.client.SuggestGasPrice(ctx.context); err == nil && suggested.Cmp(price) > 0 {
+		price = suggested
+	}
+	rawTx := types.NewTransaction(tx.tx.Nonce(), r.account.account.Address, new(big.Int), params.TxGas, price, nil)
+	return r.send(ctx, tx, rawTx, true)
+}
+func (r *TxReplacer) checkReplaceable(ctx *Context, tx *Transaction) error {
+	from, err := r.signer.GetSender(tx)
+	if err != nil {
+		return err
+	}
+	if from.address != r.account.account.Address {
+		return fmt.Errorf("transaction sent by %x, not %x", from.address, r.account.account.Address)
+	}
+	nonce, err := r.client.client.NonceAt(ctx.context, from.address, nil)
+	if err != nil {
+		return err
+	}
+	if tx.tx.Nonce() < nonce {
+		return errors.New("transaction nonce already mined")
+	}
+	return nil
+}
+func (r *TxReplacer) send(ctx *Context, original *Transaction, rawTx *types.Transaction, cancel bool) (*Replacement, error) {
+	signed, err := r.keystore.SignTxWithSigner(r.account, &Transaction{rawTx}, r.signer)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.client.SendTransaction(ctx, signed); err != nil {
+		return nil, err
+	}
+	if r.tracker != nil {
+		if err := r.tracker.Watch(&Hash{signed.tx.Hash()}); err != nil {
+			return nil, err
+		}
+	}
+	return &Replacement{
+		original:    original.tx.Hash(),
+		replacement: signed.tx,
+		cancel:      cancel,
+	}, nil
+}
@@ -0,0 +1,262 @@
+package geth
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+	ethereum "github.com/Cryptochain-VON"
+	"github.com/Cryptochain-VON/common"
+	"github.com/Cryptochain-VON/common/hexutil"
+	"github.com/Cryptochain-VON/core/types"
+	"github.com/Cryptochain-VON/event"
+	"github.com/Cryptochain-VON/rpc"
+)
+const (
+	ConnectionStateConnected    = "connected"
+	ConnectionStateDisconnected = "disconnected"
+	ConnectionStateReconnecting = "reconnecting"
+)
+const (
+	minReconnectDelay       = time.Second
+	maxReconnectDelay       = time.Minute
+	connectionProbeInterval = 30 * time.Second
+	connectionProbeTimeout  = 5 * time.Second
+	maxHeadGap              = 256
+)
+type ConnectionStateHandler interface {
+	OnConnectionStateChanged(state string)
+}
+type reconnector struct {
+	handler ConnectionStateHandler
+	probe   func() error
+	state   string
+	up      chan struct{}
+	failed  chan struct{}
+	quit    chan struct{}
+	lock    sync.Mutex
+}
+func NewResilientEthereumClient(rawurl string, handler ConnectionStateHandler) (client *EthereumClient, _ error) {
+	rawClient, err := rpc.Dial(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	client = newEthereumClient(rawClient)
+	client.resilient = newReconnector(handler, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), connectionProbeTimeout)
+		defer cancel()
+		var head hexutil.Uint64
+		return rawClient.CallContext(ctx, &head, "eth_blockNumber")
+	})
+	return client, nil
+}
+func newReconnector(handler ConnectionStateHandler, probe func() error) *reconnector {
+	r := &reconnector{
+		handler: handler,
+		probe:   probe,
+		state:   ConnectionStateConnected,
+		up:      make(chan struct{}),
+		failed:  make(chan struct{}, 1),
+		quit:    make(chan struct{}),
+	}
+	close(r.up)
+	go r.loop()
+	return r
+}
+func (ec *EthereumClient) GetConnectionState() string {
+	if ec.resilient == nil {
+		return ""
+	}
+	ec.resilient.lock.Lock()
+	defer ec.resilient.lock.Unlock()
+	return ec.resilient.state
+}
+func (r *reconnector) loop() {
+	ticker := time.NewTicker(connectionProbeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if r.probe() == nil {
+				continue
+			}
+		case <-r.failed:
+		case <-r.quit:
+			return
+		}
+		r.setState(ConnectionStateDisconnected)
+		for delay := minReconnectDelay; ; {
+			select {
+			case <-time.After(delay):
+			case <-r.quit:
+				return
+			}
+			r.setState(ConnectionStateReconnecting)
+			if r.probe() == nil {
+				break
+			}
+			if delay *= 2; delay > maxReconnectDelay {
+				delay = maxReconnectDelay
+			}
+		}
+		select {
+		case <-r.failed:
+		default:
+		}
+		r.setState(ConnectionStateConnected)
+	}
+}
+func (r *reconnector) setState(state string) {
+	r.lock.Lock()
+	changed := r.state != state
+	if changed && state == ConnectionStateConnected {
+		close(r.up)
+	}
+	if changed && r.state == ConnectionStateConnected {
+		r.up = make(chan struct{})
+	}
+	r.state = state
+	r.lock.Unlock()
+	if changed && r.handler != nil {
+		r.handler.OnConnectionStateChanged(state)
+	}
+}
+func (r *reconnector) connected() <-chan struct{} {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.up
+}
+func (r *reconnector) close() {
+	close(r.quit)
+}
+func (r *reconnector) resubscribe(quit <-chan struct{}, subscribe func() (ethereum.Subscription, error)) ethereum.Subscription {
+	for {
+		select {
+		case r.failed <- struct{}{}:
+		default:
+		}
+		select {
+		case <-time.After(minReconnectDelay):
+		case <-quit:
+			return nil
+		}
+		select {
+		case <-r.connected():
+		case <-quit:
+			return nil
+		}
+		if sub, err := subscribe(); err == nil {
+			return sub
+		}
+	}
+}
+func (ec *EthereumClient) subscribeNewHeadResilient(ctx *Context, handler NewHeadHandler, buffer int) (*Subscription, error) {
+	ch := make(chan *types.Header, buffer)
+	subscribe := func() (ethereum.Subscription, error) {
+		return ec.client.SubscribeNewHead(ctx.context, ch)
+	}
+	rawSub, err := subscribe()
+	if err != nil {
+		return nil, err
+	}
+	sub := event.NewSubscription(func(quit <-chan struct{}) error {
+		var last *types.Header
+		deliver := func(header *types.Header) {
+			if last != nil && header.Hash() == last.Hash() {
+				return
+			}
+			last = header
+			ec.cache.observeHead(header)
+			handler.OnNewHead(&Header{header})
+		}
+		for {
+			select {
+			case header := <-ch:
+				deliver(header)
+			case err := <-rawSub.Err():
+				if err == nil {
+					return nil
+				}
+				if rawSub = ec.resilient.resubscribe(quit, subscribe); rawSub == nil {
+					return nil
+				}
+				if last == nil {
+					continue
+				}
+				head, err := ec.client.HeaderByNumber(ctx.context, nil)
+				if err != nil {
+					handler.OnError(err.Error())
+					continue
+				}
+				from := new(big.Int).Add(last.Number, common.Big1)
+				if gap := new(big.Int).Sub(head.Number, big.NewInt(maxHeadGap)); gap.Cmp(from) > 0 {
+					from = gap
+				}
+				for number := from; number.Cmp(head.Number) < 0; number = new(big.Int).Add(number, common.Big1) {
+					header, err := ec.client.HeaderByNumber(ctx.context, number)
+					if err != nil {
+						handler.OnError(err.Error())
+						break
+					}
+					deliver(header)
+				}
+				if head.Number.Cmp(last.Number) >= 0 {
+					deliver(head)
+				}
+			case <-quit:
+				rawSub.Unsubscribe()
+				return nil
+			}
+		}
+	})
+	return &Subscription{sub}, nil
+}
+func (ec *EthereumClient) subscribeFilterLogsResilient(ctx *Context, query *FilterQuery, handler FilterLogsHandler, buffer int) (*Subscription, error) {
+	ch := make(chan types.Log, buffer)
+	q := query.query
+	subscribe := func() (ethereum.Subscription, error) {
+		return ec.client.SubscribeFilterLogs(ctx.context, q, ch)
+	}
+	rawSub, err := subscribe()
+	if err != nil {
+		return nil, err
+	}
+	sub := event.NewSubscription(func(quit <-chan struct{}) error {
+		dedup := &logDeduper{seen: make(map[logKey]uint64)}
+		deliver := func(log types.Log) {
+			if dedup.admit(&log) {
+				handler.OnFilterLogs(&Log{&log})
+			}
+		}
+		for {
+			select {
+			case log := <-ch:
+				deliver(log)
+			case err := <-rawSub.Err():
+				if err == nil {
+					return nil
+				}
+				if rawSub = ec.resilient.resubscribe(quit, subscribe); rawSub == nil {
+					return nil
+				}
+				if dedup.head == 0 {
+					continue
+				}
+				backfill := q
+				backfill.BlockHash = nil
+				backfill.FromBlock = new(big.Int).SetUint64(dedup.head)
+				logs, err := ec.client.FilterLogs(ctx.context, backfill)
+				if err != nil {
+					handler.OnError(err.Error())
+					continue
+				}
+				for _, log := range logs {
+					deliver(log)
+				}
+			case <-quit:
+				rawSub.Unsubscribe()
+				return nil
+			}
+		}
+	})
+	return &Subscription{sub}, nil
+}
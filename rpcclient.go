@@ -0,0 +1,125 @@
+package geth
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Cryptochain-VON/rpc"
+)
+type RPCClient struct {
+	client *rpc.Client
+}
+func NewRPCClient(rawurl string) (client *RPCClient, _ error) {
+	rawClient, err := rpc.Dial(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return &RPCClient{rawClient}, nil
+}
+func (n *Node) GetRPCClient() (client *RPCClient, _ error) {
+	rawClient, err := n.node.Attach()
+	if err != nil {
+		return nil, err
+	}
+	return &RPCClient{rawClient}, nil
+}
+func (c *RPCClient) Close() {
+	c.client.Close()
+}
+func decodeRPCParams(params string) ([]interface{}, error) {
+	if params == "" {
+		return nil, nil
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte(params), &raw); err != nil {
+		return nil, fmt.Errorf("invalid params, expected JSON array: %v", err)
+	}
+	args := make([]interface{}, len(raw))
+	for i := range raw {
+		args[i] = raw[i]
+	}
+	return args, nil
+}
+func (c *RPCClient) Call(ctx *Context, method string, params string) (result string, _ error) {
+	args, err := decodeRPCParams(params)
+	if err != nil {
+		return "", err
+	}
+	var raw json.RawMessage
+	if err := c.client.CallContext(ctx.context, &raw, method, args...); err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+var errBatchNotExecuted = errors.New("batch not executed")
+type RPCBatch struct {
+	elems    []rpc.BatchElem
+	results  []*json.RawMessage
+	executed bool
+	err      error
+}
+func NewRPCBatch() *RPCBatch {
+	return new(RPCBatch)
+}
+func (b *RPCBatch) Add(method string, params string) error {
+	args, err := decodeRPCParams(params)
+	if err != nil {
+		return err
+	}
+	result := new(json.RawMessage)
+	b.elems = append(b.elems, rpc.BatchElem{Method: method, Args: args, Result: result})
+	b.results = append(b.results, result)
+	b.executed = false
+	return nil
+}
+func (b *RPCBatch) Size() int {
+	return len(b.elems)
+}
+func (b *RPCBatch) GetResult(index int) (result string, _ error) {
+	if index < 0 || index >= len(b.elems) {
+		return "", errors.New("index out of bounds")
+	}
+	if !b.executed {
+		return "", errBatchNotExecuted
+	}
+	if b.err != nil {
+		return "", b.err
+	}
+	if err := b.elems[index].Error; err != nil {
+		return "", err
+	}
+	return string(*b.results[index]), nil
+}
+func (c *RPCClient) BatchCall(ctx *Context, batch *RPCBatch) error {
+	batch.err = c.client.BatchCallContext(ctx.context, batch.elems)
+	batch.executed = true
+	return batch.err
+}
+type RPCSubscriptionHandler interface {
+	OnMessage(result string)
+	OnError(failure string)
+}
+func (c *RPCClient) Subscribe(ctx *Context, namespace string, method string, params string, handler RPCSubscriptionHandler, buffer int) (sub *Subscription, _ error) {
+	args, err := decodeRPCParams(params)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan json.RawMessage, buffer)
+	rawSub, err := c.client.Subscribe(ctx.context, namespace, ch, append([]interface{}{method}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for {
+			select {
+			case msg := <-ch:
+				handler.OnMessage(string(msg))
+			case err := <-rawSub.Err():
+				if err != nil {
+					handler.OnError(err.Error())
+				}
+				return
+			}
+		}
+	}()
+	return &Subscription{rawSub}, nil
+}
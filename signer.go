@@ -0,0 +1,80 @@
+package geth
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"github.com/Cryptochain-VON/common"
+	"github.com/Cryptochain-VON/core/types"
+)
+const (
+	SignerFrontier  = "frontier"
+	SignerHomestead = "homestead"
+	SignerEIP155    = "eip155"
+)
+type TxSigner struct {
+	signer  types.Signer
+	fork    string
+	chainID *big.Int
+}
+func NewTxSigner(fork string, chainID *BigInt) (signer *TxSigner, _ error) {
+	switch fork {
+	case SignerFrontier:
+		return &TxSigner{signer: types.FrontierSigner{}, fork: fork}, nil
+	case SignerHomestead:
+		return &TxSigner{signer: types.HomesteadSigner{}, fork: fork}, nil
+	case SignerEIP155:
+		if chainID == nil || chainID.bigint.Sign() <= 0 {
+			return nil, errors.New("eip155 signer requires a positive chain id")
+		}
+		id := new(big.Int).Set(chainID.bigint)
+		return &TxSigner{signer: types.NewEIP155Signer(id), fork: fork, chainID: id}, nil
+	default:
+		return nil, fmt.Errorf("unknown signer fork %q", fork)
+	}
+}
+func NewLatestTxSigner(chainID *BigInt) (signer *TxSigner, _ error) {
+	return NewTxSigner(SignerEIP155, chainID)
+}
+func (p *NetworkPreset) GetTxSigner(number int64) (signer *TxSigner, _ error) {
+	if p.config == nil {
+		return nil, errors.New("network preset has no chain config")
+	}
+	switch signer := types.MakeSigner(p.config, big.NewInt(number)); signer.(type) {
+	case types.EIP155Signer:
+		return &TxSigner{signer: signer, fork: SignerEIP155, chainID: p.config.ChainID}, nil
+	case types.HomesteadSigner:
+		return &TxSigner{signer: signer, fork: SignerHomestead}, nil
+	default:
+		return &TxSigner{signer: signer, fork: SignerFrontier}, nil
+	}
+}
+func defaultTxSigner(chainID *BigInt) *TxSigner {
+	if chainID == nil {
+		return &TxSigner{signer: types.HomesteadSigner{}, fork: SignerHomestead}
+	}
+	return &TxSigner{signer: types.NewEIP155Signer(chainID.bigint), fork: SignerEIP155, chainID: chainID.bigint}
+}
+func (s *TxSigner) GetFork() string { return s.fork }
+func (s *TxSigner) IsReplayProtected() bool {
+	return s.fork == SignerEIP155
+}
+func (s *TxSigner) GetChainID() *BigInt {
+	if s.chainID == nil {
+		return nil
+	}
+	return &BigInt{new(big.Int).Set(s.chainID)}
+}
+func (s *TxSigner) GetSigHash(tx *Transaction) *Hash {
+	return &Hash{s.signer.Hash(tx.tx)}
+}
+func (s *TxSigner) GetSender(tx *Transaction) (address *Address, _ error) {
+	from, err := types.Sender(s.signer, tx.tx)
+	return &Address{from}, err
+}
+func (s *TxSigner) WithSignature(tx *Transaction, sig []byte) (signedTx *Transaction, _ error) {
+	rawTx, err := tx.tx.WithSignature(s.signer, common.CopyBytes(sig))
+	if err != nil {
+		return nil, err
+	}
+	return &Transaction{rawTx}, nil
+}
@@ -0,0 +1,262 @@
+package geth
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	ethereum "github.com/Cryptochain-VON"
+	"github.com/Cryptochain-VON/common"
+	"github.com/Cryptochain-VON/core/types"
+)
+const (
+	defaultTrackerConfirmations = 12
+	defaultTrackerDropTimeout   = 30 * time.Minute
+)
+type TxTrackerHandler interface {
+	OnPending(hash *Hash)
+	OnIncluded(hash *Hash, receipt *Receipt)
+	OnConfirmed(hash *Hash, confirmations int)
+	OnReorged(hash *Hash)
+	OnDropped(hash *Hash)
+	OnError(failure string)
+}
+type trackedTx struct {
+	Hash          common.Hash `json:"hash"`
+	Pending       bool        `json:"pending"`
+	BlockHash     common.Hash `json:"blockHash"`
+	BlockNumber   uint64      `json:"blockNumber"`
+	Confirmations uint64      `json:"confirmations"`
+	LastSeen      int64       `json:"lastSeen"`
+}
+type TxTracker struct {
+	client        *EthereumClient
+	handler       TxTrackerHandler
+	path          string
+	confirmations uint64
+	dropTimeout   time.Duration
+	txs           map[common.Hash]*trackedTx
+	sub           *Subscription
+	scan          sync.Mutex
+	lock          sync.Mutex
+}
+func NewTxTracker(client *EthereumClient, handler TxTrackerHandler, path string) (tracker *TxTracker, _ error) {
+	t := &TxTracker{
+		client:        client,
+		handler:       handler,
+		path:          path,
+		confirmations: defaultTrackerConfirmations,
+		dropTimeout:   defaultTrackerDropTimeout,
+		txs:           make(map[common.Hash]*trackedTx),
+	}
+	if path != "" {
+		blob, err := ioutil.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if len(blob) > 0 {
+			var txs []*trackedTx
+			if err := json.Unmarshal(blob, &txs); err != nil {
+				return nil, err
+			}
+			for _, tx := range txs {
+				t.txs[tx.Hash] = tx
+			}
+		}
+	}
+	return t, nil
+}
+func (t *TxTracker) SetConfirmations(confirmations int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.confirmations = uint64(confirmations)
+}
+func (t *TxTracker) SetDropTimeout(seconds int64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.dropTimeout = time.Duration(seconds) * time.Second
+}
+func (t *TxTracker) GetWatched() *Hashes {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	hashes := make([]common.Hash, 0, len(t.txs))
+	for hash := range t.txs {
+		hashes = append(hashes, hash)
+	}
+	return &Hashes{hashes}
+}
+func (t *TxTracker) Watch(hash *Hash) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if _, ok := t.txs[hash.hash]; ok {
+		return nil
+	}
+	t.txs[hash.hash] = &trackedTx{Hash: hash.hash, LastSeen: time.Now().Unix()}
+	return t.persist()
+}
+func (t *TxTracker) Unwatch(hash *Hash) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.txs, hash.hash)
+	return t.persist()
+}
+func (t *TxTracker) persist() error {
+	if t.path == "" {
+		return nil
+	}
+	txs := make([]*trackedTx, 0, len(t.txs))
+	for _, tx := range t.txs {
+		txs = append(txs, tx)
+	}
+	blob, err := json.Marshal(txs)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(t.path, blob, 0600)
+}
+func (t *TxTracker) Start(ctx *Context) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.sub != nil {
+		return errors.New("tracker already started")
+	}
+	sub, err := t.client.SubscribeNewHead(ctx, &trackerHeadHandler{t, ctx}, 16)
+	if err != nil {
+		return err
+	}
+	t.sub = sub
+	go func() {
+		head, err := t.client.client.HeaderByNumber(ctx.context, nil)
+		if err != nil {
+			t.handler.OnError(err.Error())
+			return
+		}
+		t.update(ctx, head)
+	}()
+	return nil
+}
+func (t *TxTracker) Stop() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.sub != nil {
+		t.sub.Unsubscribe()
+		t.sub = nil
+	}
+}
+type trackerHeadHandler struct {
+	tracker *TxTracker
+	ctx     *Context
+}
+func (h *trackerHeadHandler) OnNewHead(header *Header) { h.tracker.update(h.ctx, header.header) }
+func (h *trackerHeadHandler) OnError(failure string)   { h.tracker.handler.OnError(failure) }
+func (t *TxTracker) update(ctx *Context, head *types.Header) {
+	t.scan.Lock()
+	defer t.scan.Unlock()
+	t.lock.Lock()
+	txs := make([]trackedTx, 0, len(t.txs))
+	for _, tx := range t.txs {
+		txs = append(txs, *tx)
+	}
+	confirmations, dropTimeout := t.confirmations, t.dropTimeout
+	t.lock.Unlock()
+	type result struct {
+		changed bool
+		dropped bool
+		events  []func()
+	}
+	results := make([]result, len(txs))
+	for i := range txs {
+		emit := func(event func()) { results[i].events = append(results[i].events, event) }
+		results[i].changed, results[i].dropped = t.check(ctx, head, &txs[i], dropTimeout, emit)
+	}
+	var events []func()
+	t.lock.Lock()
+	changed := false
+	for i, tx := range txs {
+		current, ok := t.txs[tx.Hash]
+		if !ok {
+			continue
+		}
+		events = append(events, results[i].events...)
+		switch {
+		case results[i].dropped:
+			delete(t.txs, tx.Hash)
+			changed = true
+		case tx.BlockNumber != 0 && tx.Confirmations >= confirmations:
+			delete(t.txs, tx.Hash)
+			changed = true
+		default:
+			*current = tx
+			changed = changed || results[i].changed
+		}
+	}
+	if changed {
+		if err := t.persist(); err != nil {
+			events = append(events, func() { t.handler.OnError(err.Error()) })
+		}
+	}
+	t.lock.Unlock()
+	for _, event := range events {
+		event()
+	}
+}
+func (t *TxTracker) check(ctx *Context, head *types.Header, tx *trackedTx, dropTimeout time.Duration, emit func(func())) (changed bool, dropped bool) {
+	hash := &Hash{tx.Hash}
+	receipt, err := t.client.client.TransactionReceipt(ctx.context, tx.Hash)
+	if err != nil && err != ethereum.NotFound {
+		emit(func() { t.handler.OnError(err.Error()) })
+		return false, false
+	}
+	if receipt != nil && receipt.BlockNumber != nil {
+		if tx.BlockHash != receipt.BlockHash {
+			if tx.BlockNumber != 0 {
+				emit(func() { t.handler.OnReorged(hash) })
+			}
+			tx.Pending = false
+			tx.BlockHash = receipt.BlockHash
+			tx.BlockNumber = receipt.BlockNumber.Uint64()
+			tx.Confirmations = 0
+			emit(func() { t.handler.OnIncluded(hash, &Receipt{receipt}) })
+			changed = true
+		}
+		if number := head.Number.Uint64(); number >= tx.BlockNumber {
+			if confirmations := number - tx.BlockNumber + 1; confirmations > tx.Confirmations {
+				tx.Confirmations = confirmations
+				emit(func() { t.handler.OnConfirmed(hash, int(confirmations)) })
+				changed = true
+			}
+		}
+		tx.LastSeen = time.Now().Unix()
+		return changed, false
+	}
+	if tx.BlockNumber != 0 {
+		emit(func() { t.handler.OnReorged(hash) })
+		tx.BlockHash, tx.BlockNumber, tx.Confirmations = common.Hash{}, 0, 0
+		changed = true
+	}
+	_, pending, err := t.client.client.TransactionByHash(ctx.context, tx.Hash)
+	switch {
+	case err == nil && pending:
+		tx.LastSeen = time.Now().Unix()
+		if !tx.Pending {
+			tx.Pending = true
+			emit(func() { t.handler.OnPending(hash) })
+			changed = true
+		}
+	case err == nil:
+		tx.LastSeen = time.Now().Unix()
+	case err == ethereum.NotFound:
+		if time.Since(time.Unix(tx.LastSeen, 0)) > dropTimeout {
+			emit(func() { t.handler.OnDropped(hash) })
+			return changed, true
+		}
+	default:
+		emit(func() { t.handler.OnError(err.Error()) })
+	}
+	return changed, false
+}
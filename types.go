@@ -174,14 +174,9 @@ func (tx *Transaction) GetValue() *BigInt    { return &BigInt{tx.tx.Value()} }
 func (tx *Transaction) GetNonce() int64      { return int64(tx.tx.Nonce()) }
 func (tx *Transaction) GetHash() *Hash   { return &Hash{tx.tx.Hash()} }
 func (tx *Transaction) GetCost() *BigInt { return &BigInt{tx.tx.Cost()} }
-func (tx *Transaction) GetSigHash() *Hash { return &Hash{types.HomesteadSigner{}.Hash(tx.tx)} }
+func (tx *Transaction) GetSigHash() *Hash { return defaultTxSigner(nil).GetSigHash(tx) }
 func (tx *Transaction) GetFrom(chainID *BigInt) (address *Address, _ error) {
-	var signer types.Signer = types.HomesteadSigner{}
-	if chainID != nil {
-		signer = types.NewEIP155Signer(chainID.bigint)
-	}
-	from, err := types.Sender(signer, tx.tx)
-	return &Address{from}, err
+	return defaultTxSigner(chainID).GetSender(tx)
 }
 func (tx *Transaction) GetTo() *Address {
 	if to := tx.tx.To(); to != nil {
@@ -190,12 +185,7 @@ func (tx *Transaction) GetTo() *Address {
 	return nil
 }
 func (tx *Transaction) WithSignature(sig []byte, chainID *BigInt) (signedTx *Transaction, _ error) {
-	var signer types.Signer = types.HomesteadSigner{}
-	if chainID != nil {
-		signer = types.NewEIP155Signer(chainID.bigint)
-	}
-	rawTx, err := tx.tx.WithSignature(signer, common.CopyBytes(sig))
-	return &Transaction{rawTx}, err
+	return defaultTxSigner(chainID).WithSignature(tx, sig)
 }
 type Transactions struct{ txs types.Transactions }
 func (txs *Transactions) Size() int {
@@ -1,11 +1,67 @@
 package geth
 import (
+	"encoding/json"
 	"errors"
+	"github.com/Cryptochain-VON/common"
 	"github.com/Cryptochain-VON/core/types"
+	"github.com/Cryptochain-VON/rlp"
 )
 type Log struct {
 	log *types.Log
 }
+type rlpLog struct {
+	Address     common.Address
+	Topics      []common.Hash
+	Data        []byte
+	BlockNumber uint64
+	TxHash      common.Hash
+	TxIndex     uint
+	BlockHash   common.Hash
+	Index       uint
+	Removed     bool
+}
+func newRLPLog(log *types.Log) *rlpLog {
+	if log == nil {
+		return new(rlpLog)
+	}
+	return &rlpLog{log.Address, log.Topics, log.Data, log.BlockNumber, log.TxHash, log.TxIndex, log.BlockHash, log.Index, log.Removed}
+}
+func (l *rlpLog) log() *types.Log {
+	return &types.Log{
+		Address:     l.Address,
+		Topics:      l.Topics,
+		Data:        l.Data,
+		BlockNumber: l.BlockNumber,
+		TxHash:      l.TxHash,
+		TxIndex:     l.TxIndex,
+		BlockHash:   l.BlockHash,
+		Index:       l.Index,
+		Removed:     l.Removed,
+	}
+}
+func NewLogFromRLP(data []byte) (*Log, error) {
+	var dec rlpLog
+	if err := rlp.DecodeBytes(common.CopyBytes(data), &dec); err != nil {
+		return nil, err
+	}
+	return &Log{dec.log()}, nil
+}
+func (l *Log) EncodeRLP() ([]byte, error) {
+	return rlp.EncodeToBytes(newRLPLog(l.log))
+}
+func NewLogFromJSON(data string) (*Log, error) {
+	l := &Log{
+		log: new(types.Log),
+	}
+	if err := json.Unmarshal([]byte(data), l.log); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+func (l *Log) EncodeJSON() (string, error) {
+	data, err := json.Marshal(l.log)
+	return string(data), err
+}
 func (l *Log) GetAddress() *Address  { return &Address{l.log.Address} }
 func (l *Log) GetTopics() *Hashes    { return &Hashes{l.log.Topics} }
 func (l *Log) GetData() []byte       { return l.log.Data }
@@ -14,7 +70,47 @@ func (l *Log) GetTxHash() *Hash      { return &Hash{l.log.TxHash} }
 func (l *Log) GetTxIndex() int       { return int(l.log.TxIndex) }
 func (l *Log) GetBlockHash() *Hash   { return &Hash{l.log.BlockHash} }
 func (l *Log) GetIndex() int         { return int(l.log.Index) }
+func (l *Log) IsRemoved() bool       { return l.log.Removed }
 type Logs struct{ logs []*types.Log }
+func NewLogs(size int) *Logs {
+	logs := make([]*types.Log, size)
+	for i := range logs {
+		logs[i] = new(types.Log)
+	}
+	return &Logs{logs}
+}
+func NewLogsEmpty() *Logs {
+	return NewLogs(0)
+}
+func NewLogsFromRLP(data []byte) (*Logs, error) {
+	var dec []*rlpLog
+	if err := rlp.DecodeBytes(common.CopyBytes(data), &dec); err != nil {
+		return nil, err
+	}
+	l := &Logs{logs: make([]*types.Log, len(dec))}
+	for i, log := range dec {
+		l.logs[i] = log.log()
+	}
+	return l, nil
+}
+func (l *Logs) EncodeRLP() ([]byte, error) {
+	enc := make([]*rlpLog, len(l.logs))
+	for i, log := range l.logs {
+		enc[i] = newRLPLog(log)
+	}
+	return rlp.EncodeToBytes(enc)
+}
+func NewLogsFromJSON(data string) (*Logs, error) {
+	l := new(Logs)
+	if err := json.Unmarshal([]byte(data), &l.logs); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+func (l *Logs) EncodeJSON() (string, error) {
+	data, err := json.Marshal(l.logs)
+	return string(data), err
+}
 func (l *Logs) Size() int {
 	return len(l.logs)
 }
@@ -24,3 +120,13 @@ func (l *Logs) Get(index int) (log *Log, _ error) {
 	}
 	return &Log{l.logs[index]}, nil
 }
+func (l *Logs) Set(index int, log *Log) error {
+	if index < 0 || index >= len(l.logs) {
+		return errors.New("index out of bounds")
+	}
+	l.logs[index] = log.log
+	return nil
+}
+func (l *Logs) Append(log *Log) {
+	l.logs = append(l.logs, log.log)
+}
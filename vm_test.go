@@ -0,0 +1,45 @@
+package geth
+import (
+	"reflect"
+	"testing"
+	"github.com/Cryptochain-VON/common"
+	"github.com/Cryptochain-VON/core/types"
+)
+func TestLogRLPRoundTrip(t *testing.T) {
+	log := &types.Log{
+		Address:     common.HexToAddress("0x1"),
+		Topics:      []common.Hash{common.HexToHash("0x2"), common.HexToHash("0x3")},
+		Data:        []byte{0x04, 0x05},
+		BlockNumber: 6,
+		TxHash:      common.HexToHash("0x7"),
+		TxIndex:     8,
+		BlockHash:   common.HexToHash("0x9"),
+		Index:       10,
+		Removed:     true,
+	}
+	enc, err := (&Log{log}).EncodeRLP()
+	if err != nil {
+		t.Fatalf("failed to encode log: %v", err)
+	}
+	dec, err := NewLogFromRLP(enc)
+	if err != nil {
+		t.Fatalf("failed to decode log: %v", err)
+	}
+	if !reflect.DeepEqual(dec.log, log) {
+		t.Errorf("log mismatch: have %+v, want %+v", dec.log, log)
+	}
+	if !dec.IsRemoved() {
+		t.Errorf("removed flag lost in round trip")
+	}
+	enc, err = (&Logs{[]*types.Log{log}}).EncodeRLP()
+	if err != nil {
+		t.Fatalf("failed to encode logs: %v", err)
+	}
+	logs, err := NewLogsFromRLP(enc)
+	if err != nil {
+		t.Fatalf("failed to decode logs: %v", err)
+	}
+	if logs.Size() != 1 || !reflect.DeepEqual(logs.logs[0], log) {
+		t.Errorf("logs mismatch: have %+v, want %+v", logs.logs, log)
+	}
+}